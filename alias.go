@@ -0,0 +1,77 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// nolint: gochecknoglobals
+var (
+	aliasesMu sync.RWMutex
+	aliases   = map[reflect.Type][]interface{}{}
+)
+
+// RegisterAlias restricts a defined type, such as `type LogFormat string`, to
+// the given allowed values. Fields of that type (or pointers and slices of it)
+// are still parsed through their underlying kind, but Parse returns an error
+// if the result is not one of the allowed values.
+//
+// It is meant to be called from an init function next to the type and its
+// constants, and panics if T's underlying kind is not supported out of the box
+// or if no values are given.
+func RegisterAlias[T comparable](allowed ...T) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if _, ok := defaultBuiltInParsers[t.Kind()]; !ok {
+		panic(fmt.Sprintf("env: RegisterAlias called with unsupported type %s", t))
+	}
+	if len(allowed) == 0 {
+		panic(fmt.Sprintf("env: RegisterAlias called without allowed values for %s", t))
+	}
+	values := make([]interface{}, 0, len(allowed))
+	for _, v := range allowed {
+		values = append(values, v)
+	}
+
+	aliasesMu.Lock()
+	defer aliasesMu.Unlock()
+	aliases[t] = values
+}
+
+// checkAlias returns an error if typee was registered with RegisterAlias and v
+// is not one of its allowed values.
+func checkAlias(typee reflect.Type, v reflect.Value) error {
+	aliasesMu.RLock()
+	allowed, ok := aliases[typee]
+	aliasesMu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	value := v.Interface()
+	names := make([]string, 0, len(allowed))
+	for _, a := range allowed {
+		if a == value {
+			return nil
+		}
+		names = append(names, fmt.Sprint(a))
+	}
+	return fmt.Errorf("%v is not one of: %s", value, strings.Join(names, ", "))
+}
+
+// allowedValues returns the values registered for t with RegisterAlias, as
+// they are written in variables, or nil if there are none.
+func allowedValues(t reflect.Type) []string {
+	aliasesMu.RLock()
+	allowed, ok := aliases[t]
+	aliasesMu.RUnlock()
+	if !ok {
+		return nil
+	}
+	values := make([]string, 0, len(allowed))
+	for _, a := range allowed {
+		values = append(values, fmt.Sprint(a))
+	}
+	return values
+}
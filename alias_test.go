@@ -0,0 +1,68 @@
+package env
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type logFormat string
+
+const (
+	logFormatJSON logFormat = "json"
+	logFormatText logFormat = "text"
+)
+
+type port uint16
+
+// nolint: gochecknoinits
+func init() {
+	RegisterAlias(logFormatJSON, logFormatText)
+	RegisterAlias[port](80, 443)
+}
+
+func TestAlias(t *testing.T) {
+	type config struct {
+		Format    logFormat   `env:"FORMAT" envDefault:"text"`
+		FormatPtr *logFormat  `env:"FORMAT"`
+		Formats   []logFormat `env:"FORMATS"`
+		Port      port        `env:"PORT"`
+	}
+	defer os.Clearenv()
+	os.Setenv("FORMATS", "json,text")
+	os.Setenv("PORT", "443")
+
+	var cfg config
+	assert.NoError(t, Parse(&cfg))
+	assert.Equal(t, logFormatText, cfg.Format)
+	assert.Equal(t, []logFormat{logFormatJSON, logFormatText}, cfg.Formats)
+	assert.Equal(t, port(443), cfg.Port)
+}
+
+func TestAliasNotAllowed(t *testing.T) {
+	type config struct {
+		Format  logFormat   `env:"FORMAT"`
+		Formats []logFormat `env:"FORMATS"`
+		Port    port        `env:"PORT"`
+	}
+	defer os.Clearenv()
+
+	var cfg config
+	os.Setenv("FORMAT", "xml")
+	assert.EqualError(t, Parse(&cfg), `env: parse error on field "Format" of type "env.logFormat" (FORMAT="xml"): xml is not one of: json, text`)
+
+	os.Clearenv()
+	os.Setenv("FORMATS", "json,yaml")
+	assert.EqualError(t, Parse(&cfg), `env: parse error on field "Formats" of type "[]env.logFormat" (FORMATS="json,yaml"): yaml is not one of: json, text`)
+
+	os.Clearenv()
+	os.Setenv("PORT", "8080")
+	assert.EqualError(t, Parse(&cfg), `env: parse error on field "Port" of type "env.port" (PORT="8080"): 8080 is not one of: 80, 443`)
+}
+
+func TestRegisterAliasPanics(t *testing.T) {
+	type unsupported struct{}
+	assert.Panics(t, func() { RegisterAlias(unsupported{}) })
+	assert.Panics(t, func() { RegisterAlias[logFormat]() })
+}
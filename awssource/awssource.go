@@ -0,0 +1,117 @@
+// Package awssource provides env.Source implementations backed by AWS Systems
+// Manager Parameter Store and AWS Secrets Manager.
+//
+// To keep the AWS SDK out of the dependencies of applications that do not
+// need it, the package talks to AWS through the small ParameterStore and
+// SecretsManager interfaces, which take a few lines to implement on top of
+// the SDK clients:
+//
+//	type ssmClient struct{ *ssm.Client }
+//
+//	func (c ssmClient) GetParameter(ctx context.Context, name string) (string, error) {
+//		out, err := c.Client.GetParameter(ctx, &ssm.GetParameterInput{
+//			Name:           aws.String(name),
+//			WithDecryption: aws.Bool(true),
+//		})
+//		var notFound *types.ParameterNotFound
+//		if errors.As(err, &notFound) {
+//			return "", awssource.ErrNotFound
+//		}
+//		if err != nil {
+//			return "", err
+//		}
+//		return aws.ToString(out.Parameter.Value), nil
+//	}
+package awssource
+
+import (
+	"context"
+	"errors"
+
+	"github.com/conradludgate/env/v6"
+	"github.com/conradludgate/env/v6/internal/memo"
+)
+
+// ErrNotFound is returned, possibly wrapped, by clients when a parameter or
+// secret does not exist, in which case the variable is considered unset.
+var ErrNotFound = errors.New("awssource: not found")
+
+// ParameterStore fetches decrypted parameters from SSM Parameter Store.
+type ParameterStore interface {
+	GetParameter(ctx context.Context, name string) (string, error)
+}
+
+// SecretsManager fetches the string value of secrets from Secrets Manager.
+type SecretsManager interface {
+	GetSecretValue(ctx context.Context, secretID string) (string, error)
+}
+
+// Source is an env.Source resolving each variable from the parameter or
+// secret named after it. Values are fetched on first use and cached, so a
+// Source is meant to be used for a single Parse, or a few at startup.
+type Source struct {
+	// Name maps the name of a variable to the name of the parameter or
+	// secret holding its value.
+	Name func(key string) string
+
+	get func(ctx context.Context, name string) (string, error)
+
+	cache memo.Cache[result]
+}
+
+type result struct {
+	value string
+	ok    bool
+}
+
+var _ env.ContextSource = (*Source)(nil)
+
+// NewParameterStore returns a Source reading the variable KEY from the
+// parameter named path followed by KEY, e.g. /myapp/prod/DB_PASSWORD for the
+// path /myapp/prod/.
+func NewParameterStore(client ParameterStore, path string) *Source {
+	return &Source{
+		Name: prefixed(path),
+		get:  client.GetParameter,
+	}
+}
+
+// NewSecretsManager returns a Source reading the variable KEY from the secret
+// named prefix followed by KEY, e.g. myapp/prod/DB_PASSWORD for the prefix
+// myapp/prod/.
+func NewSecretsManager(client SecretsManager, prefix string) *Source {
+	return &Source{
+		Name: prefixed(prefix),
+		get:  client.GetSecretValue,
+	}
+}
+
+func prefixed(prefix string) func(string) string {
+	return func(key string) string {
+		return prefix + key
+	}
+}
+
+// Lookup retrieves the value of the variable named by key. Errors other than
+// ErrNotFound are ignored; env.Parse reports them through LookupContext.
+func (s *Source) Lookup(key string) (string, bool) {
+	value, ok, _ := s.LookupContext(context.Background(), key)
+	return value, ok
+}
+
+// LookupContext retrieves the value of the variable named by key. Lookups
+// of different variables run concurrently, e.g. with env.WithConcurrency,
+// while concurrent lookups of the same variable share one fetch.
+func (s *Source) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	r, err := s.cache.Get(ctx, key, func(ctx context.Context) (result, error) {
+		value, err := s.get(ctx, s.Name(key))
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return result{}, err
+		}
+		return result{value: value, ok: err == nil}, nil
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return r.value, r.ok, nil
+}
@@ -0,0 +1,86 @@
+package awssource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/conradludgate/env/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	values map[string]string
+	calls  int
+	err    error
+}
+
+func (f *fakeStore) get(_ context.Context, name string) (string, error) {
+	f.calls++
+	if f.err != nil {
+		return "", f.err
+	}
+	v, ok := f.values[name]
+	if !ok {
+		return "", fmt.Errorf("parameter %s: %w", name, ErrNotFound)
+	}
+	return v, nil
+}
+
+func (f *fakeStore) GetParameter(ctx context.Context, name string) (string, error) {
+	return f.get(ctx, name)
+}
+
+func (f *fakeStore) GetSecretValue(ctx context.Context, id string) (string, error) {
+	return f.get(ctx, id)
+}
+
+type config struct {
+	Password string `env:"DB_PASSWORD,required"`
+	Port     int    `env:"PORT" envDefault:"3000"`
+	Home     string `env:"HOME"`
+}
+
+func TestParameterStore(t *testing.T) {
+	defer os.Clearenv()
+	os.Setenv("HOME", "/home/user")
+	store := &fakeStore{values: map[string]string{"/myapp/prod/DB_PASSWORD": "secret"}}
+	src := NewParameterStore(store, "/myapp/prod/")
+
+	var cfg config
+	require.NoError(t, env.Parse(&cfg, env.WithSource(env.ChainSource(env.OSSource{}, src))))
+	assert.Equal(t, config{Password: "secret", Port: 3000, Home: "/home/user"}, cfg)
+	assert.Equal(t, 2, store.calls)
+
+	v, ok := src.Lookup("DB_PASSWORD")
+	assert.True(t, ok)
+	assert.Equal(t, "secret", v)
+	assert.Equal(t, 2, store.calls)
+}
+
+func TestSecretsManager(t *testing.T) {
+	store := &fakeStore{values: map[string]string{"myapp/db-password": "secret"}}
+	src := NewSecretsManager(store, "myapp/")
+	src.Name = func(key string) string {
+		return map[string]string{"DB_PASSWORD": "myapp/db-password"}[key]
+	}
+
+	var cfg config
+	require.NoError(t, env.Parse(&cfg, env.WithSource(src)))
+	assert.Equal(t, "secret", cfg.Password)
+}
+
+func TestSourceError(t *testing.T) {
+	store := &fakeStore{err: errors.New("access denied")}
+	src := NewParameterStore(store, "/myapp/")
+
+	var cfg config
+	err := env.Parse(&cfg, env.WithSource(src))
+	assert.EqualError(t, err, `env: could not look up "DB_PASSWORD": access denied`)
+
+	_, ok := src.Lookup("DB_PASSWORD")
+	assert.False(t, ok)
+}
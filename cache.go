@@ -0,0 +1,85 @@
+package env
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SourceCache is a Source caching the lookups of another one, as returned by
+// CachedSource.
+type SourceCache struct {
+	source Source
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value   string
+	exists  bool
+	expires time.Time
+}
+
+var (
+	_ ContextSource = (*SourceCache)(nil)
+	_ Enumerator    = (*SourceCache)(nil)
+)
+
+// CachedSource wraps s so that each variable, set or not, is looked up at
+// most once per ttl, so that repeated Parse calls do not hammer a remote
+// secret store. Failed lookups are not cached.
+func CachedSource(s Source, ttl time.Duration) *SourceCache {
+	return &SourceCache{
+		source:  s,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]cacheEntry{},
+	}
+}
+
+// Lookup retrieves the value of the variable named by key.
+func (c *SourceCache) Lookup(key string) (string, bool) {
+	value, exists, _ := c.LookupContext(context.Background(), key)
+	return value, exists
+}
+
+// LookupContext retrieves the value of the variable named by key.
+func (c *SourceCache) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(e.expires) {
+		return e.value, e.exists, nil
+	}
+
+	value, exists, err := lookupContext(ctx, c.source, key)
+	if err != nil {
+		return "", false, err
+	}
+	c.mu.Lock()
+	c.entries[key] = cacheEntry{value: value, exists: exists, expires: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+	return value, exists, nil
+}
+
+// Keys lists the variables of the wrapped Source, which is not cached.
+func (c *SourceCache) Keys(prefix string) ([]string, error) {
+	return Keys(prefix, c.source)
+}
+
+// Invalidate drops the cached values of the given variables, or of all of
+// them if none is given, forcing them to be looked up again.
+func (c *SourceCache) Invalidate(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(keys) == 0 {
+		c.entries = map[string]cacheEntry{}
+		return
+	}
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+}
@@ -0,0 +1,78 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingSource struct {
+	values map[string]string
+	calls  map[string]int
+	err    error
+}
+
+func (s *countingSource) Lookup(key string) (string, bool) {
+	v, ok, _ := s.LookupContext(context.Background(), key)
+	return v, ok
+}
+
+func (s *countingSource) LookupContext(_ context.Context, key string) (string, bool, error) {
+	s.calls[key]++
+	if s.err != nil {
+		return "", false, s.err
+	}
+	v, ok := s.values[key]
+	return v, ok, nil
+}
+
+func TestCachedSource(t *testing.T) {
+	type config struct {
+		Password string `env:"PASSWORD"`
+		Port     int    `env:"PORT" envDefault:"3000"`
+	}
+	src := &countingSource{values: map[string]string{"PASSWORD": "secret"}, calls: map[string]int{}}
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := CachedSource(src, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	var cfg config
+	for i := 0; i < 3; i++ {
+		require.NoError(t, Parse(&cfg, WithSource(cache)))
+	}
+	assert.Equal(t, config{Password: "secret", Port: 3000}, cfg)
+	assert.Equal(t, map[string]int{"PASSWORD": 1, "PORT": 1}, src.calls)
+
+	src.values["PASSWORD"] = "rotated"
+	require.NoError(t, Parse(&cfg, WithSource(cache)))
+	assert.Equal(t, "secret", cfg.Password)
+
+	cache.Invalidate("PASSWORD")
+	require.NoError(t, Parse(&cfg, WithSource(cache)))
+	assert.Equal(t, "rotated", cfg.Password)
+	assert.Equal(t, map[string]int{"PASSWORD": 2, "PORT": 1}, src.calls)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, Parse(&cfg, WithSource(cache)))
+	assert.Equal(t, map[string]int{"PASSWORD": 3, "PORT": 2}, src.calls)
+
+	cache.Invalidate()
+	src.err = errors.New("unavailable")
+	assert.Error(t, Parse(&cfg, WithSource(cache)))
+	src.err = nil
+	require.NoError(t, Parse(&cfg, WithSource(cache)))
+	assert.Equal(t, map[string]int{"PASSWORD": 5, "PORT": 3}, src.calls)
+}
+
+func TestCachedSourceKeys(t *testing.T) {
+	keys, err := Keys("A", CachedSource(MapSource{"A": "1", "AB": "2", "B": "3"}, time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "AB"}, keys)
+
+	_, err = Keys("A", CachedSource(SourceFunc(func(string) (string, bool) { return "", false }), time.Minute))
+	assert.Equal(t, ErrNotEnumerable, err)
+}
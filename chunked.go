@@ -0,0 +1,39 @@
+package env
+
+import (
+	"strconv"
+	"strings"
+)
+
+// lookupChunks looks up the value of a field with the `chunked` option,
+// split across the variables KEY_0, KEY_1, and so on, to work around limits
+// on the size of each variable, such as the 4 KB of AWS Lambda. The chunks
+// are concatenated in order, up to the first one that is not set. If KEY_0
+// is not set, the value is read from KEY itself, so that values small
+// enough need not be split. The chunks read are recorded as used, for
+// strict mode and WithUnset.
+func (p *parser) lookupChunks(key string) (string, bool, Source, error) {
+	first, exists, from, err := p.lookupFrom(key + "_0")
+	if err != nil || !exists {
+		if err == nil {
+			return p.lookupFrom(key)
+		}
+		return "", false, nil, err
+	}
+	p.used[key+"_0"] = true
+	var b strings.Builder
+	b.WriteString(first)
+	for i := 1; ; i++ {
+		chunk := key + "_" + strconv.Itoa(i)
+		value, ok, _, err := p.lookupFrom(chunk)
+		if err != nil {
+			return "", false, nil, err
+		}
+		if !ok {
+			break
+		}
+		p.used[chunk] = true
+		b.WriteString(value)
+	}
+	return b.String(), true, from, nil
+}
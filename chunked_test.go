@@ -0,0 +1,34 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunked(t *testing.T) {
+	type config struct {
+		Bundle string `env:"BUNDLE,chunked,required"`
+		Small  string `env:"SMALL,chunked"`
+	}
+	src := MapSource{
+		"APP_BUNDLE_0": "aaa",
+		"APP_BUNDLE_1": "bbb",
+		"APP_BUNDLE_2": "ccc",
+		"APP_BUNDLE_4": "ignored",
+		"APP_SMALL":    "x",
+	}
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(src), WithPrefix("APP_")))
+	assert.Equal(t, config{Bundle: "aaabbbccc", Small: "x"}, cfg)
+
+	err := Parse(&config{}, WithSource(src), WithPrefix("APP_"), WithStrict())
+	assert.EqualError(t, err, `env: unknown environment variables with prefix "APP_": APP_BUNDLE_4`)
+
+	delete(src, "APP_BUNDLE_4")
+	require.NoError(t, Parse(&config{}, WithSource(src), WithPrefix("APP_"), WithStrict()))
+
+	err = Parse(&config{}, WithSource(MapSource{"BUNDLE_1": "bbb"}))
+	assert.EqualError(t, err, `env: required environment variable "BUNDLE" is not set`)
+}
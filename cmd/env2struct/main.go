@@ -0,0 +1,127 @@
+// Command env2struct generates a Go configuration struct, with `env` tags,
+// from the variables of .env files or of the process environment, to ease
+// the adoption of env in existing projects.
+//
+// Usage:
+//
+//	env2struct [-prefix APP_] [-name Config] [-package config] [-env .env] [-o config.go]
+//
+// The type of each field is inferred from the value of its variable, as
+// env.Infer does. Keys are made relative to -prefix, for the struct to be
+// parsed with env.WithPrefix.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/conradludgate/env/v6"
+	"github.com/conradludgate/env/v6/dotenv"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Environ(), os.Stdout, os.Stderr))
+}
+
+type envFiles []string
+
+func (f *envFiles) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *envFiles) Set(path string) error {
+	*f = append(*f, path)
+	return nil
+}
+
+func run(args, environ []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("env2struct", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	prefix := fs.String("prefix", "", "only use the variables starting with `prefix`, and strip it from the keys")
+	name := fs.String("name", "Config", "`name` of the generated struct")
+	pkg := fs.String("package", "config", "`name` of the package of the generated file")
+	out := fs.String("o", "", "write the generated code to `file` instead of the standard output")
+	var files envFiles
+	fs.Var(&files, "env", "read the variables of this .env `file` instead of the environment; may be repeated")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	vars := map[string]string{}
+	if len(files) > 0 {
+		var err error
+		if vars, err = dotenv.Read(files...); err != nil {
+			fmt.Fprintf(stderr, "env2struct: %v\n", err)
+			return 2
+		}
+	} else {
+		for _, kv := range environ {
+			if k, v, ok := strings.Cut(kv, "="); ok {
+				vars[k] = v
+			}
+		}
+	}
+
+	src, err := generate(*pkg, *name, *prefix, vars)
+	if err != nil {
+		fmt.Fprintf(stderr, "env2struct: %v\n", err)
+		return 2
+	}
+	if *out == "" {
+		stdout.Write(src)
+		return 0
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		fmt.Fprintf(stderr, "env2struct: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// generate returns the formatted source of a file of package pkg declaring
+// the struct name, with a field for each of the variables starting with
+// prefix.
+func generate(pkg, name, prefix string, vars map[string]string) ([]byte, error) {
+	infos, err := env.Infer(env.WithPrefix(prefix), env.WithSource(env.MapSource(vars)))
+	if err != nil {
+		return nil, err
+	}
+	def, err := env.StructDefinition(name, infos)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	imports := map[string]bool{}
+	for _, info := range infos {
+		if path := info.Type.PkgPath(); path != "" {
+			imports[path] = true
+		}
+	}
+	if len(imports) > 0 {
+		paths := make([]string, 0, len(imports))
+		for path := range imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		b.WriteString("import (\n")
+		for _, path := range paths {
+			fmt.Fprintf(&b, "\t%q\n", path)
+		}
+		b.WriteString(")\n\n")
+	}
+	if prefix != "" {
+		fmt.Fprintf(&b, "// %s is the configuration read from the environment, with the prefix %s.\n", name, prefix)
+	} else {
+		fmt.Fprintf(&b, "// %s is the configuration read from the environment.\n", name)
+	}
+	b.Write(def)
+	return format.Source(b.Bytes())
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-prefix", "APP_", "-name", "Settings"}, []string{
+		"APP_PORT=8080",
+		"APP_DEBUG=true",
+		"APP_TIMEOUT=5s",
+		"APP_DATABASE_URL=postgres://db:5432/app",
+		"APP_API_KEY=secret",
+		"HOME=/root",
+	}, &stdout, &stderr)
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stderr.String())
+	assert.Equal(t, `package config
+
+import (
+	"net/url"
+	"time"
+)
+
+// Settings is the configuration read from the environment, with the prefix APP_.
+type Settings struct {
+	APIKey      string        `+"`env:\"API_KEY\"`"+`
+	DatabaseURL url.URL       `+"`env:\"DATABASE_URL\"`"+`
+	Debug       bool          `+"`env:\"DEBUG\"`"+`
+	Port        int           `+"`env:\"PORT\"`"+`
+	Timeout     time.Duration `+"`env:\"TIMEOUT\"`"+`
+}
+`, stdout.String())
+}
+
+func TestRunEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	dotenv := filepath.Join(dir, ".env")
+	require.NoError(t, os.WriteFile(dotenv, []byte("HOST=localhost\nRETRIES=3\n"), 0o600))
+	out := filepath.Join(dir, "config.go")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-env", dotenv, "-package", "main", "-o", out}, []string{"IGNORED=1"}, &stdout, &stderr)
+	assert.Equal(t, 0, code)
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, `package main
+
+// Config is the configuration read from the environment.
+type Config struct {
+	Host    string `+"`env:\"HOST\"`"+`
+	Retries int    `+"`env:\"RETRIES\"`"+`
+}
+`, string(b))
+}
+
+func TestRunErrors(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 2, run([]string{"-env", filepath.Join(t.TempDir(), "missing")}, nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "no such file or directory")
+}
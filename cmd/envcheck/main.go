@@ -0,0 +1,226 @@
+// Command envcheck validates an environment against the JSON Schema of a
+// configuration struct, as written by env.JSONSchema, for CI jobs and
+// container entrypoints to check it before starting a program.
+//
+// Usage:
+//
+//	envcheck -schema config.schema.json [-prefix APP_] [-env .env]
+//
+// It checks the process environment, or the variables of the given .env
+// files, and reports required variables that are not set, values that do
+// not match their schema and, with -prefix, variables starting with the
+// prefix that the schema does not describe. It exits with status 1 if it
+// found any problem, and 2 if it could not run.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/conradludgate/env/v6/dotenv"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Environ(), os.Stdout, os.Stderr))
+}
+
+// schema is the subset of JSON Schema written by env.JSONSchema.
+type schema struct {
+	Properties        map[string]property `json:"properties"`
+	PatternProperties map[string]property `json:"patternProperties"`
+	Required          []string            `json:"required"`
+	AllOf             []struct {
+		AnyOf []struct {
+			Required []string `json:"required"`
+		} `json:"anyOf"`
+	} `json:"allOf"`
+}
+
+type property struct {
+	Enum      []string `json:"enum"`
+	Pattern   string   `json:"pattern"`
+	Format    string   `json:"format"`
+	WriteOnly bool     `json:"writeOnly"`
+}
+
+type envFiles []string
+
+func (f *envFiles) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *envFiles) Set(path string) error {
+	*f = append(*f, path)
+	return nil
+}
+
+func run(args, environ []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("envcheck", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schemaPath := fs.String("schema", "", "path to the JSON Schema of the configuration, as written by env.JSONSchema")
+	prefix := fs.String("prefix", "", "report variables starting with `prefix` that the schema does not describe")
+	var files envFiles
+	fs.Var(&files, "env", "check the variables of this .env `file` instead of the environment; may be repeated")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *schemaPath == "" {
+		fmt.Fprintln(stderr, "envcheck: -schema is required")
+		fs.Usage()
+		return 2
+	}
+
+	s, err := readSchema(*schemaPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "envcheck: %v\n", err)
+		return 2
+	}
+	vars := map[string]string{}
+	if len(files) > 0 {
+		if vars, err = dotenv.Read(files...); err != nil {
+			fmt.Fprintf(stderr, "envcheck: %v\n", err)
+			return 2
+		}
+	} else {
+		for _, kv := range environ {
+			if k, v, ok := strings.Cut(kv, "="); ok {
+				vars[k] = v
+			}
+		}
+	}
+
+	problems, err := check(s, vars, *prefix)
+	if err != nil {
+		fmt.Fprintf(stderr, "envcheck: %v\n", err)
+		return 2
+	}
+	for _, p := range problems {
+		fmt.Fprintln(stdout, p)
+	}
+	if len(problems) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func readSchema(path string) (schema, error) {
+	var s schema
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return s, fmt.Errorf("invalid schema %s: %v", path, err)
+	}
+	return s, nil
+}
+
+// check returns the problems of vars against s, sorted by variable.
+func check(s schema, vars map[string]string, prefix string) ([]string, error) {
+	var problems []string
+	for _, key := range s.Required {
+		if _, ok := vars[key]; !ok {
+			problems = append(problems, key+": required variable is not set")
+		}
+	}
+	// alternatives, such as a chunked variable or its first chunk
+	for _, all := range s.AllOf {
+		var keys []string
+		found := false
+		for _, alt := range all.AnyOf {
+			missing := false
+			for _, key := range alt.Required {
+				if _, ok := vars[key]; !ok {
+					missing = true
+				}
+			}
+			found = found || !missing
+			keys = append(keys, strings.Join(alt.Required, " and "))
+		}
+		if !found && len(keys) > 0 {
+			problems = append(problems, keys[0]+": required variable is not set, nor "+strings.Join(keys[1:], ", nor "))
+		}
+	}
+	patterns := make(map[*regexp.Regexp]property, len(s.PatternProperties))
+	for pattern, prop := range s.PatternProperties {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema for %s: %v", pattern, err)
+		}
+		patterns[re] = prop
+	}
+	for key, value := range vars {
+		props := []property{}
+		if prop, ok := s.Properties[key]; ok {
+			props = append(props, prop)
+		}
+		for re, prop := range patterns {
+			if re.MatchString(key) {
+				props = append(props, prop)
+			}
+		}
+		if len(props) == 0 {
+			if prefix != "" && strings.HasPrefix(key, prefix) {
+				problems = append(problems, key+": unknown variable")
+			}
+			continue
+		}
+		for _, prop := range props {
+			msg, err := validate(prop, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid schema for %s: %v", key, err)
+			}
+			if msg == "" {
+				continue
+			}
+			if prop.WriteOnly {
+				problems = append(problems, fmt.Sprintf("%s: invalid value, %s", key, msg))
+			} else {
+				problems = append(problems, fmt.Sprintf("%s: invalid value %q, %s", key, value, msg))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems, nil
+}
+
+// validate returns why value does not match prop, or an empty string if it
+// does.
+func validate(prop property, value string) (string, error) {
+	if len(prop.Enum) > 0 {
+		for _, v := range prop.Enum {
+			if v == value {
+				return "", nil
+			}
+		}
+		return "expected one of " + strings.Join(prop.Enum, ", "), nil
+	}
+	if prop.Pattern != "" {
+		re, err := regexp.Compile(prop.Pattern)
+		if err != nil {
+			return "", err
+		}
+		if !re.MatchString(value) {
+			return "does not match " + prop.Pattern, nil
+		}
+	}
+	switch prop.Format {
+	case "uri-reference":
+		if _, err := url.Parse(value); err != nil {
+			return "expected a URL", nil
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return "expected an RFC 3339 date and time", nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/conradludgate/env/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type config struct {
+	Host     string        `env:"HOST,required"`
+	Port     int           `env:"PORT" envDefault:"8080"`
+	Debug    bool          `env:"DEBUG"`
+	Timeout  time.Duration `env:"TIMEOUT"`
+	Since    time.Time     `env:"SINCE"`
+	Password string        `env:"PASSWORD,required,sensitive"`
+}
+
+func writeSchema(t *testing.T) string {
+	b, err := env.JSONSchema((*config)(nil), env.WithPrefix("APP_"))
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(path, b, 0o600))
+	return path
+}
+
+func TestRun(t *testing.T) {
+	schema := writeSchema(t)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-schema", schema, "-prefix", "APP_"}, []string{
+		"APP_HOST=localhost",
+		"APP_PORT=eighty",
+		"APP_DEBUG=yes",
+		"APP_TIMEOUT=5s",
+		"APP_SINCE=yesterday",
+		"APP_PROT=8080",
+		"HOME=/root",
+	}, &stdout, &stderr)
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stderr.String())
+	assert.Equal(t, `APP_DEBUG: invalid value "yes", expected one of 1, t, T, TRUE, true, True, 0, f, F, FALSE, false, False
+APP_PASSWORD: required variable is not set
+APP_PORT: invalid value "eighty", does not match ^[+-]?[0-9]+$
+APP_PROT: unknown variable
+APP_SINCE: invalid value "yesterday", expected an RFC 3339 date and time
+`, stdout.String())
+
+	stdout.Reset()
+	code = run([]string{"-schema", schema}, []string{"APP_HOST=localhost", "APP_PASSWORD=hunter", "APP_PROT=8080"}, &stdout, &stderr)
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout.String())
+}
+
+func TestRunEnvFile(t *testing.T) {
+	schema := writeSchema(t)
+	dotenv := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(dotenv, []byte("APP_HOST=localhost\nAPP_PASSWORD=1\nAPP_TIMEOUT=soon\n"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-schema", schema, "-env", dotenv}, []string{"APP_TIMEOUT=5s"}, &stdout, &stderr)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout.String(), `APP_TIMEOUT: invalid value "soon", does not match`)
+}
+
+func TestRunRawValues(t *testing.T) {
+	type config struct {
+		Port   int       `env:"PORT_FILE,file"`
+		Since  time.Time `env:"SINCE,relative"`
+		Debug  bool      `env:"DEBUG,trim"`
+		Bundle string    `env:"BUNDLE,chunked,required"`
+	}
+	b, err := env.JSONSchema((*config)(nil), env.WithPrefix("APP_"))
+	require.NoError(t, err)
+	schema := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(schema, b, 0o600))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-schema", schema, "-prefix", "APP_"}, []string{
+		"APP_PORT_FILE=/run/secrets/port",
+		"APP_SINCE=now-15m",
+		"APP_DEBUG=true\n",
+		"APP_BUNDLE_0=abc",
+		"APP_BUNDLE_1=def",
+	}, &stdout, &stderr)
+	assert.Empty(t, stderr.String())
+	assert.Empty(t, stdout.String())
+	assert.Equal(t, 0, code)
+
+	stdout.Reset()
+	code = run([]string{"-schema", schema, "-prefix", "APP_"}, []string{"APP_BUNDLE_1=def"}, &stdout, &stderr)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "APP_BUNDLE: required variable is not set, nor APP_BUNDLE_0\n", stdout.String())
+}
+
+func TestRunSensitive(t *testing.T) {
+	s := schema{Properties: map[string]property{"TOKEN": {Pattern: "^[a-f0-9]+$", WriteOnly: true}}}
+	problems, err := check(s, map[string]string{"TOKEN": "s3cr3t!"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TOKEN: invalid value, does not match ^[a-f0-9]+$"}, problems)
+}
+
+func TestRunErrors(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 2, run(nil, nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "envcheck: -schema is required")
+
+	stderr.Reset()
+	assert.Equal(t, 2, run([]string{"-schema", filepath.Join(t.TempDir(), "missing.json")}, nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "no such file or directory")
+}
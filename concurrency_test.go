@@ -0,0 +1,128 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type benchConfig struct {
+	Home     string            `env:"HOME"`
+	Port     int               `env:"PORT" envDefault:"3000"`
+	Hosts    []string          `env:"HOSTS"`
+	Timeout  time.Duration     `env:"TIMEOUT" envDefault:"5s"`
+	Labels   map[string]string `env:"LABELS"`
+	Level    LogLevel          `env:"LOG_LEVEL" envDefault:"info"`
+	Database struct {
+		URL string `env:"URL"`
+	} `envPrefix:"DB_"`
+}
+
+type tenantName string
+
+func TestParseConcurrent(t *testing.T) {
+	defer os.Clearenv()
+	for i := 0; i < 8; i++ {
+		os.Setenv(fmt.Sprintf("T%d_NAME", i), fmt.Sprintf("tenant-%d", i))
+		os.Setenv(fmt.Sprintf("T%d_HOSTS", i), "a,b,c")
+	}
+
+	type config struct {
+		Name  tenantName `env:"NAME"`
+		Hosts []string   `env:"HOSTS"`
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				var cfg config
+				funcs := map[reflect.Type]ParserFunc{
+					reflect.TypeOf(tenantName("")): func(v string) (interface{}, error) {
+						return tenantName(fmt.Sprintf("%s#%d", v, i)), nil
+					},
+				}
+				err := ParsePrefix(fmt.Sprintf("T%d_", i), &cfg, WithFuncs(funcs))
+				assert.NoError(t, err)
+				assert.Equal(t, tenantName(fmt.Sprintf("tenant-%d#%d", i, i)), cfg.Name)
+				assert.Equal(t, []string{"a", "b", "c"}, cfg.Hosts)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCustomParsersDoNotLeak(t *testing.T) {
+	before := len(defaultTypeParsers)
+	type config struct {
+		Name tenantName `env:"NAME"`
+	}
+	var cfg config
+	assert.NoError(t, ParseWithFuncs(&cfg, map[reflect.Type]ParserFunc{
+		reflect.TypeOf(tenantName("")): func(v string) (interface{}, error) {
+			return tenantName(v), nil
+		},
+	}))
+	assert.Len(t, defaultTypeParsers, before)
+}
+
+func setBenchEnv(b *testing.B) {
+	b.Helper()
+	os.Setenv("HOME", "/tmp/fakehome")
+	os.Setenv("HOSTS", "a,b,c,d")
+	os.Setenv("LABELS", "app:web,tier:frontend")
+	os.Setenv("DB_URL", "postgres://localhost:5432/db")
+}
+
+func BenchmarkParse(b *testing.B) {
+	setBenchEnv(b)
+	defer os.Clearenv()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var cfg benchConfig
+		if err := Parse(&cfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseParallel(b *testing.B) {
+	setBenchEnv(b)
+	defer os.Clearenv()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			var cfg benchConfig
+			if err := Parse(&cfg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkParseAllStrictLargeEnviron(b *testing.B) {
+	setBenchEnv(b)
+	defer os.Clearenv()
+	for i := 0; i < 5000; i++ {
+		os.Setenv(fmt.Sprintf("UNRELATED_%d", i), "value")
+	}
+	targets := make([]interface{}, 20)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range targets {
+			targets[j] = &benchConfig{}
+		}
+		if err := ParseAll(targets, WithStrict(), WithPrefix("BENCH_")); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
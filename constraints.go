@@ -0,0 +1,181 @@
+package env
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WithRequireOneOf makes Parse fail unless at least one variable of each of
+// the given groups is set, e.g. any one of TOKEN, USERNAME and PASSWORD:
+//
+//	type config struct {
+//		Token    string `env:"TOKEN" envGroup:"auth"`
+//		Username string `env:"USERNAME" envGroup:"auth"`
+//		Password string `env:"PASSWORD" envGroup:"auth" envRequiredIf:"USERNAME"`
+//	}
+//
+// Any variable of a group satisfies it on its own, so USERNAME alone is
+// enough here were it not for the `envRequiredIf` tag of Password, which
+// also requires PASSWORD when USERNAME is set.
+//
+// Fields join groups with the `envGroup` tag, which takes a comma-separated
+// list of names. Defaults do not count as set.
+func WithRequireOneOf(groups ...string) Option {
+	return func(o *Options) {
+		o.RequireOneOf = append(o.RequireOneOf, groups...)
+	}
+}
+
+// WithMutuallyExclusive makes Parse return a ConflictError if several
+// variables of any of the given groups are set, e.g. for alternative ways of
+// providing the same setting, rather than silently preferring one:
+//
+//	type config struct {
+//		CertFile string `env:"TLS_CERT_FILE" envGroup:"tls_cert"`
+//		CertPEM  string `env:"TLS_CERT_PEM" envGroup:"tls_cert"`
+//	}
+//
+// Defaults do not count as set. Combined with WithRequireOneOf, exactly one
+// variable of the group must be set.
+func WithMutuallyExclusive(groups ...string) Option {
+	return func(o *Options) {
+		o.Exclusive = append(o.Exclusive, groups...)
+	}
+}
+
+// ConflictError is returned by Parse when several variables of a group
+// declared with WithMutuallyExclusive are set.
+type ConflictError struct {
+	Group string
+	// Keys lists the variables set, in the order of the fields.
+	Keys []string
+}
+
+func (e ConflictError) Error() string {
+	var keys string
+	switch n := len(e.Keys); n {
+	case 0:
+		keys = "several variables"
+	case 1:
+		keys = e.Keys[0] + " and other variables"
+	default:
+		keys = strings.Join(e.Keys[:n-1], ", ") + " and " + e.Keys[n-1]
+	}
+	return fmt.Sprintf("env: %s cannot be set together (group %q)", keys, e.Group)
+}
+
+type groupMember struct {
+	key string
+	set bool
+}
+
+// addToGroups records whether the variable of params is set, for the
+// constraints on its groups.
+func (p *parser) addToGroups(params FieldParams, set bool) {
+	for _, group := range params.Groups {
+		p.groups[group] = append(p.groups[group], groupMember{key: params.Key, set: set})
+	}
+}
+
+// checkGroups returns an error for the first group of Exclusive with several
+// variables set, or of RequireOneOf with none. Missing variables are
+// reported through OnWarning instead with RequiredAsWarning.
+func (p *parser) checkGroups() error {
+	for _, group := range p.Exclusive {
+		members, ok := p.groups[group]
+		if !ok {
+			return fmt.Errorf("env: no field in group %q", group)
+		}
+		if countSet(members) < 2 {
+			continue
+		}
+		var keys []string
+		for _, m := range members {
+			if m.set {
+				keys = append(keys, m.key)
+			}
+		}
+		return ConflictError{Group: group, Keys: keys}
+	}
+	for _, group := range p.RequireOneOf {
+		members, ok := p.groups[group]
+		if !ok {
+			return fmt.Errorf("env: no field in group %q", group)
+		}
+		if countSet(members) > 0 {
+			continue
+		}
+		err := fmt.Errorf("env: one of %s must be set (group %q)", memberKeys(members), group)
+		if !p.RequiredAsWarning {
+			return err
+		}
+		p.warn(err)
+	}
+	return nil
+}
+
+func countSet(members []groupMember) int {
+	n := 0
+	for _, m := range members {
+		if m.set {
+			n++
+		}
+	}
+	return n
+}
+
+func memberKeys(members []groupMember) string {
+	keys := make([]string, len(members))
+	for i, m := range members {
+		keys[i] = m.key
+	}
+	return strings.Join(keys, ", ")
+}
+
+// checkConditions returns an error for the first field tagged with
+// `envRequiredIf` whose variable is not set although its condition holds.
+// The variable of the condition is looked up in the values of the fields
+// parsed, defaults included, then in the Source. Missing variables are
+// reported through OnWarning instead with RequiredAsWarning.
+func (p *parser) checkConditions() error {
+	for _, params := range p.conditional {
+		value, ok := p.values[params.RequiredIfKey]
+		if !ok {
+			var err error
+			if value, _, err = p.lookupSource(params.RequiredIfKey); err != nil {
+				return err
+			}
+		}
+		if !conditionHolds(value, params.RequiredIfValue) {
+			continue
+		}
+		cond := params.RequiredIfKey
+		if params.RequiredIfValue != "" {
+			cond += "=" + params.RequiredIfValue
+		} else {
+			cond += " is set"
+		}
+		err := fmt.Errorf(`env: environment variable %q is required when %s`, params.Key, cond)
+		if !p.RequiredAsWarning {
+			return err
+		}
+		p.warn(err)
+	}
+	return nil
+}
+
+// conditionHolds reports whether value matches want, the value of an
+// `envRequiredIf` condition. Booleans match whatever their spelling, e.g. 1
+// matches true, and an empty want matches any non-empty value.
+func conditionHolds(value, want string) bool {
+	if want == "" {
+		return value != ""
+	}
+	if a, err := strconv.ParseBool(value); err == nil {
+		if b, err := strconv.ParseBool(want); err == nil {
+			return a == b
+		}
+	}
+	return value == want
+}
@@ -0,0 +1,100 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireOneOf(t *testing.T) {
+	type config struct {
+		Token    string `env:"TOKEN" envGroup:"auth"`
+		Username string `env:"USERNAME" envGroup:"auth,basic"`
+		Password string `env:"PASSWORD" envGroup:"auth,basic" envDefault:"hunter2"`
+	}
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(MapSource{"APP_TOKEN": "t"}), WithPrefix("APP_"), WithRequireOneOf("auth")))
+	require.NoError(t, Parse(&cfg, WithSource(MapSource{"APP_USERNAME": "u"}), WithPrefix("APP_"), WithRequireOneOf("auth", "basic")))
+
+	err := Parse(&cfg, WithSource(MapSource{}), WithPrefix("APP_"), WithRequireOneOf("auth"))
+	assert.EqualError(t, err, `env: one of APP_TOKEN, APP_USERNAME, APP_PASSWORD must be set (group "auth")`)
+
+	err = Parse(&cfg, WithSource(MapSource{"APP_TOKEN": "t"}), WithPrefix("APP_"), WithRequireOneOf("auth", "basic"))
+	assert.EqualError(t, err, `env: one of APP_USERNAME, APP_PASSWORD must be set (group "basic")`)
+
+	err = Parse(&cfg, WithSource(MapSource{}), WithRequireOneOf("oauth"))
+	assert.EqualError(t, err, `env: no field in group "oauth"`)
+
+	// any one variable satisfies a group, envRequiredIf ties the others
+	type basic struct {
+		Token    string `env:"TOKEN" envGroup:"auth"`
+		Username string `env:"USERNAME" envGroup:"auth"`
+		Password string `env:"PASSWORD" envGroup:"auth" envRequiredIf:"USERNAME"`
+	}
+	require.NoError(t, Parse(&basic{}, WithSource(MapSource{"TOKEN": "t"}), WithRequireOneOf("auth")))
+	err = Parse(&basic{}, WithSource(MapSource{"USERNAME": "u"}), WithRequireOneOf("auth"))
+	assert.EqualError(t, err, `env: environment variable "PASSWORD" is required when USERNAME is set`)
+
+	var warnings []error
+	require.NoError(t, Parse(&cfg, WithSource(MapSource{}), WithRequireOneOf("auth"), WithRequiredAsWarning(), WithWarningHook(func(err error) {
+		warnings = append(warnings, err)
+	})))
+	assert.Len(t, warnings, 1)
+}
+
+func TestMutuallyExclusive(t *testing.T) {
+	type config struct {
+		CertFile string `env:"TLS_CERT_FILE" envGroup:"tls_cert"`
+		CertPEM  string `env:"TLS_CERT_PEM" envGroup:"tls_cert"`
+		CertURL  string `env:"TLS_CERT_URL" envGroup:"tls_cert" envDefault:"https://example.com/cert"`
+	}
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(MapSource{"TLS_CERT_PEM": "pem"}), WithMutuallyExclusive("tls_cert")))
+	require.NoError(t, Parse(&cfg, WithSource(MapSource{}), WithMutuallyExclusive("tls_cert")))
+
+	err := Parse(&cfg, WithSource(MapSource{"TLS_CERT_FILE": "cert.pem", "TLS_CERT_PEM": "pem"}), WithMutuallyExclusive("tls_cert"))
+	assert.EqualError(t, err, `env: TLS_CERT_FILE and TLS_CERT_PEM cannot be set together (group "tls_cert")`)
+	assert.Equal(t, ConflictError{Group: "tls_cert", Keys: []string{"TLS_CERT_FILE", "TLS_CERT_PEM"}}, err)
+	assert.EqualError(t, ConflictError{Group: "g", Keys: []string{"A"}}, `env: A and other variables cannot be set together (group "g")`)
+	assert.EqualError(t, ConflictError{}, `env: several variables cannot be set together (group "")`)
+
+	err = Parse(&cfg, WithSource(MapSource{"TLS_CERT_FILE": "a", "TLS_CERT_PEM": "b", "TLS_CERT_URL": "c"}), WithMutuallyExclusive("tls_cert"))
+	assert.EqualError(t, err, `env: TLS_CERT_FILE, TLS_CERT_PEM and TLS_CERT_URL cannot be set together (group "tls_cert")`)
+
+	// exactly one
+	err = Parse(&cfg, WithSource(MapSource{}), WithMutuallyExclusive("tls_cert"), WithRequireOneOf("tls_cert"))
+	assert.EqualError(t, err, `env: one of TLS_CERT_FILE, TLS_CERT_PEM, TLS_CERT_URL must be set (group "tls_cert")`)
+}
+
+func TestRequiredIf(t *testing.T) {
+	type tls struct {
+		Enabled bool   `env:"ENABLED" envDefault:"false"`
+		Cert    string `env:"CERT" envRequiredIf:"ENABLED=true"`
+		Key     string `env:"KEY" envRequiredIf:"ENABLED=true"`
+	}
+	type config struct {
+		TLS      tls    `envPrefix:"TLS_"`
+		Mode     string `env:"MODE" envDefault:"local"`
+		Region   string `env:"REGION" envRequiredIf:"MODE=cloud"`
+		ProxyURL string `env:"PROXY_URL" envRequiredIf:"PROXY_USER"`
+	}
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(MapSource{})))
+	require.NoError(t, Parse(&cfg, WithSource(MapSource{"TLS_ENABLED": "1", "TLS_CERT": "c", "TLS_KEY": "k"})))
+
+	err := Parse(&cfg, WithSource(MapSource{"TLS_ENABLED": "1", "TLS_CERT": "c"}))
+	assert.EqualError(t, err, `env: environment variable "TLS_KEY" is required when TLS_ENABLED=true`)
+
+	err = Parse(&cfg, WithSource(MapSource{"APP_MODE": "cloud"}), WithPrefix("APP_"))
+	assert.EqualError(t, err, `env: environment variable "APP_REGION" is required when APP_MODE=cloud`)
+
+	err = Parse(&cfg, WithSource(MapSource{"PROXY_USER": "me"}))
+	assert.EqualError(t, err, `env: environment variable "PROXY_URL" is required when PROXY_USER is set`)
+
+	var warnings []error
+	require.NoError(t, Parse(&cfg, WithSource(MapSource{"MODE": "cloud"}), WithRequiredAsWarning(), WithWarningHook(func(err error) {
+		warnings = append(warnings, err)
+	})))
+	assert.Len(t, warnings, 1)
+}
@@ -0,0 +1,118 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// VarInfo describes a variable read by Parse, as returned by Describe. The
+// embedded FieldParams hold its key, the path of its field, its default and
+// its tag options.
+type VarInfo struct {
+	FieldParams
+
+	// Type is the type of the field.
+	Type reflect.Type
+
+	// Separator is the `envSeparator` of slices and maps, or its default,
+	// and is empty for other types.
+	Separator string
+
+	// Docs is the `envDocs` tag of the field.
+	Docs string
+}
+
+// Describe lists the variables Parse reads for v, a struct or a pointer to a
+// struct, in the order of the fields, without looking them up. It is meant
+// for documentation, validation or UI tooling, and accepts the options of
+// Parse that change the keys, such as WithPrefix.
+//
+// Nested structs are described whether or not Parse would reach them, i.e.
+// pointers to structs are described even if nil.
+func Describe(v interface{}, opts ...Option) ([]VarInfo, error) {
+	return newParser(opts).describe(v)
+}
+
+// MissingVars returns the keys of the required variables of v, a struct or a
+// pointer to a struct, that are not set in the Source, in the order of the
+// fields, without populating v. It lets deploy pipelines check an
+// environment before rolling it out:
+//
+//	missing, err := env.MissingVars((*config)(nil), env.WithPrefix("APP_"))
+//
+// As with Describe, the variables of nested structs are checked even if v
+// holds nil pointers to them.
+func MissingVars(v interface{}, opts ...Option) ([]string, error) {
+	p := newParser(opts)
+	infos, err := p.describe(v)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, info := range infos {
+		if !info.Required {
+			continue
+		}
+		if err := p.ctx.Err(); err != nil {
+			return nil, fmt.Errorf("env: %w", err)
+		}
+		_, exists, err := p.lookupSource(info.Key)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			missing = append(missing, info.Key)
+		}
+	}
+	return missing, nil
+}
+
+func (p *parser) describe(v interface{}) ([]VarInfo, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("env: expected a struct or a pointer to a struct, got %T", v)
+	}
+	var infos []VarInfo
+	if err := p.describeStruct(&infos, p.Prefix, "", t); err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+func (p *parser) describeStruct(infos *[]VarInfo, prefix, path string, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !p.Unexported {
+			continue
+		}
+		params, err := p.fieldParams(prefix, path+sf.Name, sf)
+		if err != nil {
+			return err
+		}
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if params.Key == "" {
+			if ft.Kind() == reflect.Struct {
+				if err := p.describeStruct(infos, prefix+sf.Tag.Get("envPrefix"), path+sf.Name+".", ft); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		info := VarInfo{
+			FieldParams: params,
+			Type:        sf.Type,
+			Docs:        sf.Tag.Get("envDocs"),
+		}
+		if ft.Kind() == reflect.Slice || ft.Kind() == reflect.Map {
+			info.Separator = tagOr(sf, "envSeparator", ",")
+		}
+		*infos = append(*infos, info)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe(t *testing.T) {
+	type database struct {
+		URL string `env:"URL,required" envDocs:"Connection string"`
+	}
+	type config struct {
+		Home     string            `env:"HOME,noprefix"`
+		Port     int               `env:"PORT" envDefault:"8080"`
+		Hosts    []string          `env:"HOSTS" envSeparator:";"`
+		Labels   map[string]string `env:"LABELS"`
+		Timeout  time.Duration     `env:"TIMEOUT"`
+		Password Secret            `env:"PASSWORD"`
+		Database *database         `envPrefix:"DB_"`
+		Ignored  string
+		private  string `env:"PRIVATE"`
+	}
+
+	infos, err := Describe((*config)(nil), WithPrefix("APP_"))
+	require.NoError(t, err)
+	assert.Equal(t, []VarInfo{
+		{FieldParams: FieldParams{Field: "Home", OwnKey: "HOME", Key: "HOME", NoPrefix: true}, Type: reflect.TypeOf("")},
+		{FieldParams: FieldParams{Field: "Port", OwnKey: "PORT", Key: "APP_PORT", DefaultValue: "8080", HasDefaultValue: true}, Type: reflect.TypeOf(0)},
+		{FieldParams: FieldParams{Field: "Hosts", OwnKey: "HOSTS", Key: "APP_HOSTS"}, Type: reflect.TypeOf([]string{}), Separator: ";"},
+		{FieldParams: FieldParams{Field: "Labels", OwnKey: "LABELS", Key: "APP_LABELS"}, Type: reflect.TypeOf(map[string]string{}), Separator: ","},
+		{FieldParams: FieldParams{Field: "Timeout", OwnKey: "TIMEOUT", Key: "APP_TIMEOUT"}, Type: reflect.TypeOf(time.Duration(0))},
+		{FieldParams: FieldParams{Field: "Password", OwnKey: "PASSWORD", Key: "APP_PASSWORD", Sensitive: true}, Type: reflect.TypeOf(Secret(""))},
+		{FieldParams: FieldParams{Field: "Database.URL", OwnKey: "URL", Key: "APP_DB_URL", Required: true}, Type: reflect.TypeOf(""), Docs: "Connection string"},
+	}, infos)
+
+	infos, err = Describe(config{}, WithUnexported())
+	require.NoError(t, err)
+	require.Len(t, infos, 8)
+	assert.Equal(t, "PRIVATE", infos[7].Key)
+}
+
+func TestDescribeErrors(t *testing.T) {
+	_, err := Describe("nope")
+	assert.EqualError(t, err, "env: expected a struct or a pointer to a struct, got string")
+
+	type config struct {
+		Port int `env:"PORT,bogus"`
+	}
+	_, err = Describe(config{})
+	assert.EqualError(t, err, `env: tag option "bogus" not supported`)
+}
+
+func TestMissingVars(t *testing.T) {
+	type database struct {
+		URL      string `env:"URL,required"`
+		Password string `env:"PASSWORD,required" envDefault:"changeme"`
+	}
+	type config struct {
+		Host     string    `env:"HOST,required"`
+		Port     int       `env:"PORT,required"`
+		Debug    bool      `env:"DEBUG"`
+		Database *database `envPrefix:"DB_"`
+	}
+
+	var cfg config
+	source := MapSource{"APP_PORT": "nope", "APP_DB_URL": ""}
+	missing, err := MissingVars(&cfg, WithPrefix("APP_"), WithSource(source))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"APP_HOST", "APP_DB_PASSWORD"}, missing)
+	assert.Equal(t, config{}, cfg)
+
+	missing, err = MissingVars(config{}, WithSource(MapSource{"HOST": "h", "PORT": "1", "DB_URL": "u", "DB_PASSWORD": "p"}))
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+
+	_, err = MissingVars("nope")
+	assert.EqualError(t, err, "env: expected a struct or a pointer to a struct, got string")
+}
@@ -0,0 +1,87 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirSource returns a Source reading each variable from the file of the same
+// name in dir, such as the secrets Docker mounts in /run/secrets or a
+// Kubernetes Secret volume: DB_PASSWORD is read from dir/DB_PASSWORD, or from
+// dir/db_password if the former does not exist. Trailing newlines are
+// trimmed, and a missing directory provides no variables.
+func DirSource(dir string) Source {
+	return dirSource(dir)
+}
+
+type dirSource string
+
+var (
+	_ ContextSource = dirSource("")
+	_ Enumerator    = dirSource("")
+)
+
+func (d dirSource) Lookup(key string) (string, bool) {
+	value, ok, _ := d.LookupContext(context.Background(), key)
+	return value, ok
+}
+
+func (d dirSource) LookupContext(_ context.Context, key string) (string, bool, error) {
+	if key == "" || strings.ContainsAny(key, `/\`) || key == "." || key == ".." {
+		return "", false, nil
+	}
+	for _, name := range []string{key, strings.ToLower(key)} {
+		b, err := os.ReadFile(filepath.Join(string(d), name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", false, err
+		}
+		return strings.TrimRight(string(b), "\r\n"), true, nil
+	}
+	return "", false, nil
+}
+
+// Keys returns the names of the files starting with prefix, upper-cased if
+// they are all lower-case, skipping hidden files and directories such as the
+// ..data link of Kubernetes volumes.
+func (d dirSource) Keys(prefix string) ([]string, error) {
+	entries, err := os.ReadDir(string(d))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") || e.IsDir() {
+			continue
+		}
+		if name == strings.ToLower(name) {
+			name = strings.ToUpper(name)
+		}
+		if strings.HasPrefix(name, prefix) {
+			keys = append(keys, name)
+		}
+	}
+	return keys, nil
+}
+
+// CredentialsSource returns a Source for services using systemd credentials
+// (LoadCredential= and friends): variables are read from the files of
+// $CREDENTIALS_DIRECTORY first, then from the process environment. Without
+// $CREDENTIALS_DIRECTORY, it is the process environment alone.
+func CredentialsSource() Source {
+	dir, ok := os.LookupEnv("CREDENTIALS_DIRECTORY")
+	if !ok || dir == "" {
+		return OSSource{}
+	}
+	return ChainSource(DirSource(dir), OSSource{})
+}
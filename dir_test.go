@@ -0,0 +1,80 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirSource(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	write("DB_PASSWORD", "secret\n")
+	write("api_key", "key\r\n")
+	write(".hidden", "x")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "..data"), 0700))
+
+	type config struct {
+		Password string `env:"DB_PASSWORD,required"`
+		APIKey   string `env:"API_KEY"`
+		Port     int    `env:"PORT" envDefault:"3000"`
+	}
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(DirSource(dir))))
+	assert.Equal(t, config{Password: "secret", APIKey: "key", Port: 3000}, cfg)
+
+	keys, err := Keys("", DirSource(dir))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"API_KEY", "DB_PASSWORD"}, keys)
+
+	_, ok := DirSource(dir).Lookup("../" + filepath.Base(dir) + "/DB_PASSWORD")
+	assert.False(t, ok)
+}
+
+func TestDirSourceMissing(t *testing.T) {
+	src := DirSource(filepath.Join(t.TempDir(), "missing"))
+	_, ok := src.Lookup("DB_PASSWORD")
+	assert.False(t, ok)
+	keys, err := Keys("", src)
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestDirSourceError(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "DB_PASSWORD"), 0700))
+
+	type config struct {
+		Password string `env:"DB_PASSWORD"`
+	}
+	var cfg config
+	err := Parse(&cfg, WithSource(DirSource(dir)))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `env: could not look up "DB_PASSWORD"`)
+}
+
+func TestCredentialsSource(t *testing.T) {
+	type config struct {
+		Password string `env:"DB_PASSWORD"`
+		Port     int    `env:"PORT"`
+	}
+	os.Clearenv()
+	defer os.Clearenv()
+	os.Setenv("DB_PASSWORD", "from-env")
+	os.Setenv("PORT", "8080")
+
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(CredentialsSource())))
+	assert.Equal(t, config{Password: "from-env", Port: 8080}, cfg)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "DB_PASSWORD"), []byte("from-credential"), 0600))
+	os.Setenv("CREDENTIALS_DIRECTORY", dir)
+	require.NoError(t, Parse(&cfg, WithSource(CredentialsSource())))
+	assert.Equal(t, config{Password: "from-credential", Port: 8080}, cfg)
+}
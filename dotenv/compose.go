@@ -0,0 +1,41 @@
+package dotenv
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/conradludgate/env/v6"
+)
+
+// MarshalCompose is like Marshal, except that it writes the env_file format
+// of docker-compose (v2), which differs from .env files in the quoting of
+// values: values are written as is when possible, otherwise in single
+// quotes, which compose takes literally, and only values containing single
+// quotes or newlines are written in double quotes, with $ escaped as $$.
+// Required variables are preceded by a comment.
+func MarshalCompose(v interface{}, opts ...env.Option) ([]byte, error) {
+	vars, err := env.MarshalVars(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for _, v := range vars {
+		if v.Required {
+			buf.WriteString("# required\n")
+		}
+		fmt.Fprintf(&buf, "%s=%s\n", v.Key, composeQuote(v.Value))
+	}
+	return buf.Bytes(), nil
+}
+
+func composeQuote(value string) string {
+	if !strings.ContainsAny(value, " \t\r\n\"'#$\\=`") {
+		return value
+	}
+	if !strings.ContainsAny(value, "'\r\n") {
+		return "'" + value + "'"
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", "$$")
+	return `"` + r.Replace(value) + `"`
+}
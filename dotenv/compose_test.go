@@ -0,0 +1,41 @@
+package dotenv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalCompose(t *testing.T) {
+	type config struct {
+		Port     int      `env:"PORT" envDefault:"3000"`
+		Host     string   `env:"HOST,required"`
+		Hosts    []string `env:"HOSTS"`
+		Password string   `env:"PASSWORD"`
+		Message  string   `env:"MESSAGE"`
+		PEM      string   `env:"PEM"`
+	}
+	b, err := MarshalCompose(config{
+		Host:     "localhost",
+		Hosts:    []string{"a", "b"},
+		Password: `p@$$ #1`,
+		Message:  `it's "fine" $HOME`,
+		PEM:      "line1\nline2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `PORT=3000
+# required
+HOST=localhost
+HOSTS=a,b
+PASSWORD='p@$$ #1'
+MESSAGE="it's \"fine\" $$HOME"
+PEM="line1\nline2"
+`, string(b))
+
+	vars, err := Read(writeFile(t, "compose.env", string(b)))
+	require.NoError(t, err)
+	assert.Equal(t, `p@$$ #1`, vars["PASSWORD"])
+	assert.Equal(t, `it's "fine" $HOME`, vars["MESSAGE"])
+	assert.Equal(t, "line1\nline2", vars["PEM"])
+}
@@ -0,0 +1,241 @@
+// Package dotenv reads .env files, made of KEY=VALUE lines, either into the
+// process environment or into a map usable as an env.Source.
+//
+// Blank lines and lines starting with # are ignored, keys may be preceded by
+// `export`, and values may be wrapped in single or double quotes. Unquoted
+// values end at the first ` #`, which starts a comment. Quoted values may span
+// several lines; single-quoted values are taken literally, while escape
+// sequences such as \n, \t or \" are interpreted in double-quoted values.
+//
+// Unquoted and double-quoted values may refer to other variables as $NAME or
+// ${NAME}. Variables defined in the same file are used first, then the ones
+// of the process environment, and $$ or \$ (in double quotes) stand for a
+// literal $.
+package dotenv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/conradludgate/env/v6"
+)
+
+// Env holds the variables read from .env files.
+type Env map[string]string
+
+var _ env.Enumerator = Env(nil)
+
+// Lookup retrieves the value of the variable named by key.
+func (e Env) Lookup(key string) (string, bool) {
+	v, ok := e[key]
+	return v, ok
+}
+
+// Keys returns the names of the variables starting with prefix.
+func (e Env) Keys(prefix string) ([]string, error) {
+	return env.MapSource(e).Keys(prefix)
+}
+
+// Load reads the given files, or .env if none is given, and sets the
+// variables they define in the process environment. Variables that are
+// already set are left untouched, so the real environment always wins.
+func Load(paths ...string) error {
+	return load(false, paths)
+}
+
+// Overload is like Load, except that it overrides variables that are already
+// set in the process environment.
+func Overload(paths ...string) error {
+	return load(true, paths)
+}
+
+func load(override bool, paths []string) error {
+	vars, err := Read(paths...)
+	if err != nil {
+		return err
+	}
+	for k, v := range vars {
+		if _, ok := os.LookupEnv(k); ok && !override {
+			continue
+		}
+		if err := os.Setenv(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read reads the given files, or .env if none is given, and returns the
+// variables they define without touching the process environment. When a
+// variable is defined in several files, the last file wins.
+func Read(paths ...string) (Env, error) {
+	if len(paths) == 0 {
+		paths = []string{".env"}
+	}
+	vars := Env{}
+	for _, path := range paths {
+		fileVars, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fileVars {
+			vars[k] = v
+		}
+	}
+	return vars, nil
+}
+
+// LoadAndParse reads the given files, or .env if none is given, ignoring the
+// ones that do not exist, and parses v with env.Parse. Variables set in the
+// process environment take precedence over the ones defined in the files,
+// and later files take precedence over earlier ones.
+func LoadAndParse(v interface{}, paths []string, opts ...env.Option) error {
+	if len(paths) == 0 {
+		paths = []string{".env"}
+	}
+	existing := existingFiles(paths)
+	vars := Env{}
+	if len(existing) > 0 {
+		var err error
+		if vars, err = Read(existing...); err != nil {
+			return err
+		}
+	}
+	source := env.WithSource(env.ChainSource(env.OSSource{}, vars))
+	return env.Parse(v, append([]env.Option{source}, opts...)...)
+}
+
+func readFile(path string) (Env, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("dotenv: %v", err)
+	}
+	if isSOPS(data) {
+		if data, err = decrypt(data); err != nil {
+			return nil, fmt.Errorf("dotenv: %s: %v", path, err)
+		}
+	}
+	vars, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("dotenv: %s: %v", path, err)
+	}
+	return vars, nil
+}
+
+// Parse parses the content of a .env file.
+func Parse(r io.Reader) (Env, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	var keys []string
+	templates := map[string]string{}
+	for i := 0; i < len(lines); i++ {
+		n := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		if value != "" && (value[0] == '"' || value[0] == '\'') {
+			// quoted values may span several lines
+			for closingQuote(value) < 0 && i+1 < len(lines) {
+				i++
+				value += "\n" + lines[i]
+			}
+		}
+		templates[key], err = parseValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		keys = append(keys, key)
+	}
+	return interpolate(keys, templates)
+}
+
+func parseLine(line string) (key, value string, err error) {
+	line = strings.TrimPrefix(line, "export ")
+	parts := strings.SplitN(line, "=", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("invalid line %q", line)
+	}
+	key = strings.TrimSpace(parts[0])
+	if key == "" || strings.ContainsAny(key, " \t") {
+		return "", "", fmt.Errorf("invalid key %q", key)
+	}
+	return key, strings.TrimSpace(parts[1]), nil
+}
+
+// parseValue parses a raw value into a template for interpolate, in which $$
+// stands for a literal $. Single-quoted values are kept as is, while escape
+// sequences such as \n are interpreted in double-quoted values.
+func parseValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if q := value[0]; q == '"' || q == '\'' {
+		end := closingQuote(value)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value %s", value)
+		}
+		if q == '\'' {
+			return strings.ReplaceAll(value[1:end], "$", "$$"), nil
+		}
+		return unescape(value[1:end]), nil
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// closingQuote returns the index of the quote closing the quoted value, or
+// -1 if there is none. In double-quoted values, quotes can be escaped.
+func closingQuote(value string) int {
+	q := value[0]
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if q == '"' {
+				i++
+			}
+		case q:
+			return i
+		}
+	}
+	return -1
+}
+
+func unescape(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '"', '\\':
+			b.WriteByte(value[i])
+		case '$':
+			b.WriteString("$$")
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,154 @@
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/conradludgate/env/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestParse(t *testing.T) {
+	vars, err := Parse(strings.NewReader(`
+# a comment
+PORT=3000
+export HOST = localhost
+EMPTY=
+DOUBLE="hello # world"
+SINGLE='single # quoted'
+INLINE=value # comment
+HASH=a#b
+`))
+	require.NoError(t, err)
+	assert.Equal(t, Env{
+		"PORT":   "3000",
+		"HOST":   "localhost",
+		"EMPTY":  "",
+		"DOUBLE": "hello # world",
+		"SINGLE": "single # quoted",
+		"INLINE": "value",
+		"HASH":   "a#b",
+	}, vars)
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse(strings.NewReader("PORT=3000\nfoo\n"))
+	assert.EqualError(t, err, `line 2: invalid line "foo"`)
+
+	_, err = Parse(strings.NewReader("MY KEY=3000\n"))
+	assert.EqualError(t, err, `line 1: invalid key "MY KEY"`)
+
+	_, err = Parse(strings.NewReader(`KEY="unterminated`))
+	assert.EqualError(t, err, `line 1: unterminated quoted value "unterminated`)
+}
+
+func TestRead(t *testing.T) {
+	first := writeFile(t, ".env", "A=1\nB=1\n")
+	second := writeFile(t, ".env.local", "B=2\nC=2\n")
+
+	vars, err := Read(first, second)
+	require.NoError(t, err)
+	assert.Equal(t, Env{"A": "1", "B": "2", "C": "2"}, vars)
+
+	_, err = Read(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	defer os.Clearenv()
+	os.Setenv("B", "from-env")
+	path := writeFile(t, ".env", "A=1\nB=1\n")
+
+	require.NoError(t, Load(path))
+	assert.Equal(t, "1", os.Getenv("A"))
+	assert.Equal(t, "from-env", os.Getenv("B"))
+}
+
+func TestOverload(t *testing.T) {
+	defer os.Clearenv()
+	os.Setenv("B", "from-env")
+	os.Setenv("D", "from-env")
+	first := writeFile(t, ".env", "A=1\nB=1\n")
+	second := writeFile(t, ".env.local", "B=2\nC=2\n")
+
+	require.NoError(t, Overload(first, second))
+	assert.Equal(t, "1", os.Getenv("A"))
+	assert.Equal(t, "2", os.Getenv("B"))
+	assert.Equal(t, "2", os.Getenv("C"))
+	assert.Equal(t, "from-env", os.Getenv("D"))
+
+	assert.Error(t, Overload(filepath.Join(t.TempDir(), "missing")))
+}
+
+func TestEnvAsSource(t *testing.T) {
+	type config struct {
+		Port int    `env:"PORT"`
+		Host string `env:"HOST" envDefault:"localhost"`
+		URL  string `env:"URL" envDefault:"http://${HOST}:${PORT}" envExpand:"true"`
+	}
+	vars, err := Parse(strings.NewReader("PORT=8080\nHOST=example.com\n"))
+	require.NoError(t, err)
+
+	var cfg config
+	require.NoError(t, env.Parse(&cfg, env.WithSource(vars)))
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, "example.com", cfg.Host)
+	assert.Equal(t, "http://example.com:8080", cfg.URL)
+}
+
+func TestParseQuoting(t *testing.T) {
+	vars, err := Parse(strings.NewReader(`PEM="-----BEGIN KEY-----
+abc
+-----END KEY-----"
+ESCAPES="a\nb\tc \"quoted\" \\ \$HOME \q"
+LITERAL='a\nb "c"'
+MULTI_LITERAL='line 1
+line 2'
+TEMPLATE="Hello, {{ .Name }}!"
+AFTER=after
+`))
+	require.NoError(t, err)
+	assert.Equal(t, Env{
+		"PEM":           "-----BEGIN KEY-----\nabc\n-----END KEY-----",
+		"ESCAPES":       "a\nb\tc \"quoted\" \\ $HOME \\q",
+		"LITERAL":       `a\nb "c"`,
+		"MULTI_LITERAL": "line 1\nline 2",
+		"TEMPLATE":      "Hello, {{ .Name }}!",
+		"AFTER":         "after",
+	}, vars)
+
+	_, err = Parse(strings.NewReader("A=1\nKEY=\"unterminated\nB=2\n"))
+	assert.EqualError(t, err, "line 2: unterminated quoted value \"unterminated\nB=2\n")
+}
+
+func TestLoadAndParse(t *testing.T) {
+	type config struct {
+		Host  string `env:"HOST"`
+		Port  int    `env:"PORT" envDefault:"3000"`
+		Debug bool   `env:"DEBUG"`
+		Name  string `env:"NAME,required"`
+	}
+	defer os.Clearenv()
+	os.Setenv("APP_HOST", "from-env")
+	first := writeFile(t, ".env", "APP_HOST=from-file\nAPP_PORT=8080\nAPP_NAME=first\n")
+	second := writeFile(t, ".env.local", "APP_NAME=second\nAPP_DEBUG=true\n")
+	missing := filepath.Join(t.TempDir(), ".env.missing")
+
+	var cfg config
+	require.NoError(t, LoadAndParse(&cfg, []string{first, missing, second}, env.WithPrefix("APP_")))
+	assert.Equal(t, config{Host: "from-env", Port: 8080, Debug: true, Name: "second"}, cfg)
+	_, ok := os.LookupEnv("APP_NAME")
+	assert.False(t, ok)
+
+	assert.EqualError(t, LoadAndParse(&cfg, []string{missing}), `env: required environment variable "NAME" is not set`)
+}
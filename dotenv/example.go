@@ -0,0 +1,117 @@
+package dotenv
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/conradludgate/env/v6"
+)
+
+// ExampleVar describes a variable of a configuration struct, as written by
+// Example.
+type ExampleVar struct {
+	// Key is the name of the variable, including the `envPrefix` of the
+	// enclosing structs.
+	Key string
+
+	// Doc is the `envDocs` tag of the field.
+	Doc string
+
+	// Type is the Go type of the field.
+	Type string
+
+	// Default is the `envDefault` tag, HasDefault whether it is set.
+	Default    string
+	HasDefault bool
+
+	// Required, File and Sensitive are set by the `required`, `file` and
+	// `sensitive` tag options, as described by env.FieldParams.
+	Required  bool
+	File      bool
+	Sensitive bool
+}
+
+// DefaultExampleTemplate is the template used by Example. It is executed
+// with a []ExampleVar.
+const DefaultExampleTemplate = `{{range $i, $v := .}}{{if $i}}
+{{end}}{{comment $v.Doc}}# {{$v.Type}}{{if $v.File}} (path to a file){{end}}{{if $v.Required}}, required{{end}}{{if $v.Sensitive}}, sensitive{{end}}
+{{if $v.HasDefault}}{{$v.Key}}={{quote $v.Default}}{{else if $v.Required}}{{$v.Key}}={{else}}# {{$v.Key}}={{end}}
+{{end}}`
+
+// Example generates a sample .env file for the struct pointed to by v,
+// listing every variable read by env.Parse along with its documentation,
+// taken from the `envDocs` tag, its type and whether it is required.
+// Variables with a default are set to it, required ones are left empty and
+// optional ones are commented out. opts are the options v is parsed with,
+// such as env.WithPrefix.
+func Example(v interface{}, opts ...env.Option) ([]byte, error) {
+	return ExampleWithTemplate(v, DefaultExampleTemplate, opts...)
+}
+
+// ExampleWithTemplate is like Example, but renders the variables with the
+// given text/template, executed with a []ExampleVar. Besides the standard
+// functions, templates can use `quote`, which quotes a value as needed to be
+// read back, and `comment`, which turns text into # comment lines.
+func ExampleWithTemplate(v interface{}, text string, opts ...env.Option) ([]byte, error) {
+	vars, err := ExampleVars(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New("example").Funcs(template.FuncMap{
+		"quote":   quote,
+		"comment": comment,
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("dotenv: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return nil, fmt.Errorf("dotenv: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ExampleVars lists the variables read by env.Parse for the struct pointed
+// to by v with opts, in declaration order, as described by env.Describe.
+func ExampleVars(v interface{}, opts ...env.Option) ([]ExampleVar, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("dotenv: expected a struct or a pointer to a struct, got %T", v)
+	}
+	infos, err := env.Describe(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	vars := make([]ExampleVar, 0, len(infos))
+	for _, info := range infos {
+		vars = append(vars, ExampleVar{
+			Key:        info.Key,
+			Doc:        info.Docs,
+			Type:       info.Type.String(),
+			Default:    info.DefaultValue,
+			HasDefault: info.HasDefaultValue,
+			Required:   info.Required,
+			File:       info.LoadFile,
+			Sensitive:  info.Sensitive,
+		})
+	}
+	return vars, nil
+}
+
+// comment turns text into # comment lines.
+func comment(text string) string {
+	if text == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		b.WriteString(strings.TrimRight("# "+line, " ") + "\n")
+	}
+	return b.String()
+}
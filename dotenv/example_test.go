@@ -0,0 +1,86 @@
+package dotenv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/conradludgate/env/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type exampleConfig struct {
+	Port     int           `env:"PORT" envDefault:"3000" envDocs:"Port the HTTP server listens on."`
+	Host     string        `env:"HOST,required" envDocs:"Public host name.\nUsed to build links."`
+	Password string        `env:"PASSWORD,required,sensitive"`
+	Cert     string        `env:"CERT,file"`
+	Timeout  time.Duration `env:"TIMEOUT"`
+	Greeting string        `env:"GREETING" envDefault:"hello world"`
+	Database *struct {
+		URL string `env:"URL" envDocs:"Database connection string."`
+	} `envPrefix:"DB_"`
+	ignored string
+}
+
+func TestExample(t *testing.T) {
+	b, err := Example(&exampleConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, `# Port the HTTP server listens on.
+# int
+PORT=3000
+
+# Public host name.
+# Used to build links.
+# string, required
+HOST=
+
+# string, required, sensitive
+PASSWORD=
+
+# string (path to a file)
+# CERT=
+
+# time.Duration
+# TIMEOUT=
+
+# string
+GREETING="hello world"
+
+# Database connection string.
+# string
+# DB_URL=
+`, string(b))
+
+	vars, err := Read(writeFile(t, ".env.example", string(b)))
+	require.NoError(t, err)
+	assert.Equal(t, Env{"PORT": "3000", "HOST": "", "PASSWORD": "", "GREETING": "hello world"}, vars)
+}
+
+func TestExampleWithTemplate(t *testing.T) {
+	b, err := ExampleWithTemplate(exampleConfig{}, `{{range .}}{{if .Required}}{{.Key}}
+{{end}}{{end}}`)
+	require.NoError(t, err)
+	assert.Equal(t, "HOST\nPASSWORD\n", string(b))
+
+	_, err = ExampleWithTemplate(exampleConfig{}, "{{")
+	assert.Error(t, err)
+}
+
+func TestExampleVars(t *testing.T) {
+	vars, err := ExampleVars(&exampleConfig{})
+	require.NoError(t, err)
+	require.Len(t, vars, 7)
+	assert.Equal(t, ExampleVar{Key: "PASSWORD", Type: "string", Required: true, Sensitive: true}, vars[2])
+
+	vars, err = ExampleVars(&exampleConfig{}, env.WithPrefix("APP_"))
+	require.NoError(t, err)
+	assert.Equal(t, "APP_PORT", vars[0].Key)
+	assert.Equal(t, "APP_DB_URL", vars[6].Key)
+
+	b, err := Example(&exampleConfig{}, env.WithPrefix("APP_"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "\nAPP_PORT=3000\n")
+
+	_, err = ExampleVars("nope")
+	assert.EqualError(t, err, "dotenv: expected a struct or a pointer to a struct, got string")
+}
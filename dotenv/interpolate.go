@@ -0,0 +1,127 @@
+package dotenv
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// interpolate resolves the references to other variables in templates, in
+// the order of keys so that errors are deterministic.
+func interpolate(keys []string, templates map[string]string) (Env, error) {
+	r := resolver{templates: templates, vars: Env{}}
+	for _, key := range keys {
+		if _, err := r.resolve(key); err != nil {
+			return nil, err
+		}
+	}
+	return r.vars, nil
+}
+
+type resolver struct {
+	templates map[string]string
+	vars      Env
+	resolving []string
+}
+
+func (r *resolver) resolve(key string) (string, error) {
+	if v, ok := r.vars[key]; ok {
+		return v, nil
+	}
+	for i, k := range r.resolving {
+		if k == key {
+			cycle := append(append([]string{}, r.resolving[i:]...), key)
+			return "", fmt.Errorf("cycle detected in expansion: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	r.resolving = append(r.resolving, key)
+	defer func() { r.resolving = r.resolving[:len(r.resolving)-1] }()
+
+	var refErr error
+	v, err := expand(r.templates[key], func(name string) (string, error) {
+		// a variable referring to itself, e.g. PATH=${PATH}:/bin, extends
+		// the value of the process environment
+		if _, ok := r.templates[name]; ok && name != key {
+			v, err := r.resolve(name)
+			refErr = err
+			return v, err
+		}
+		return os.Getenv(name), nil
+	})
+	if err != nil {
+		if err == refErr {
+			return "", err
+		}
+		return "", fmt.Errorf("%s: %w", key, err)
+	}
+	r.vars[key] = v
+	return v, nil
+}
+
+// expand replaces $NAME and ${NAME} in s with the value returned by lookup,
+// and $$ with $. A $ followed by anything else is kept as is.
+func expand(s string, lookup func(name string) (string, error)) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		var name string
+		switch c := s[i+1]; {
+		case c == '$':
+			b.WriteByte('$')
+			i++
+			continue
+		case c == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference %q", s[i:])
+			}
+			name = s[i+2 : i+end]
+			if !isName(name) {
+				return "", fmt.Errorf("invalid variable reference %q", s[i:i+end+1])
+			}
+			i += end
+		case isNameStart(c):
+			j := i + 2
+			for j < len(s) && isNameChar(s[j]) {
+				j++
+			}
+			name = s[i+1 : j]
+			i = j - 1
+		default:
+			b.WriteByte('$')
+			continue
+		}
+		v, err := lookup(name)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(v)
+	}
+	return b.String(), nil
+}
+
+func isName(s string) bool {
+	if s == "" || !isNameStart(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isNameChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || '0' <= c && c <= '9'
+}
@@ -0,0 +1,66 @@
+package dotenv
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	defer os.Clearenv()
+	os.Setenv("HOME", "/home/user")
+	os.Setenv("PATH", "/bin")
+	os.Setenv("HOST", "from-env")
+
+	vars, err := Parse(strings.NewReader(`
+URL=http://${HOST}:$PORT/path
+HOST=localhost
+PORT=3000
+DATA="${HOME}/data"
+LITERAL='${HOME}'
+ESCAPED="\${HOME} $$HOME"
+DOLLARS=a$$b $ $1 $-
+PATH=${PATH}:/usr/local/bin
+UNSET=${UNSET_VAR}
+`))
+	require.NoError(t, err)
+	assert.Equal(t, Env{
+		"URL":     "http://localhost:3000/path",
+		"HOST":    "localhost",
+		"PORT":    "3000",
+		"DATA":    "/home/user/data",
+		"LITERAL": "${HOME}",
+		"ESCAPED": "${HOME} $HOME",
+		"DOLLARS": "a$b $ $1 $-",
+		"PATH":    "/bin:/usr/local/bin",
+		"UNSET":   "",
+	}, vars)
+}
+
+func TestInterpolateErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		content string
+		err     string
+	}{
+		"cycle": {
+			content: "A=${B}\nB=${C}\nC=$A\n",
+			err:     "cycle detected in expansion: A -> B -> C -> A",
+		},
+		"unterminated": {
+			content: "A=${B\n",
+			err:     `A: unterminated variable reference "${B"`,
+		},
+		"invalid": {
+			content: "A=x\nB=${A}\nC=${B} ${1A}\n",
+			err:     `C: invalid variable reference "${1A}"`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tc.content))
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package dotenv
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/conradludgate/env/v6"
+)
+
+// Marshal writes the fields of the struct pointed to by v as KEY=value lines,
+// in the order of the fields, so that the output can be read back with Read.
+// The variables are the ones returned by env.MarshalVars, whose rules apply,
+// and values are quoted when needed. In particular, sensitive values are
+// masked unless env.WithUnredacted is given.
+func Marshal(v interface{}, opts ...env.Option) ([]byte, error) {
+	vars, err := env.MarshalVars(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for _, v := range vars {
+		fmt.Fprintf(&buf, "%s=%s\n", v.Key, quote(v.Value))
+	}
+	return buf.Bytes(), nil
+}
+
+// quote wraps value in double quotes if it would not be read back as is.
+func quote(value string) string {
+	if value == "" || !strings.ContainsAny(value, " \t\r\n\"'#$\\=") {
+		return value
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "$", `\$`)
+	return `"` + r.Replace(value) + `"`
+}
@@ -0,0 +1,119 @@
+package dotenv
+
+import (
+	"bytes"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/conradludgate/env/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type marshalInner struct {
+	Name string `env:"NAME"`
+}
+
+type marshalConfig struct {
+	Home     string            `env:"HOME"`
+	Port     int               `env:"PORT" envDefault:"3000"`
+	Debug    bool              `env:"DEBUG"`
+	Hosts    []string          `env:"HOSTS" envSeparator:":"`
+	Timeout  time.Duration     `env:"TIMEOUT"`
+	URL      url.URL           `env:"URL"`
+	Started  time.Time         `env:"STARTED"`
+	Labels   map[string]string `env:"LABELS"`
+	Matrix   [][]int           `env:"MATRIX"`
+	Message  string            `env:"MESSAGE"`
+	Ratio    *float64          `env:"RATIO"`
+	Missing  *string           `env:"MISSING"`
+	Inner    marshalInner      `envPrefix:"INNER_"`
+	InnerPtr *marshalInner     `envPrefix:"PTR_"`
+	Ignored  string
+	private  string `env:"PRIVATE"`
+}
+
+func TestMarshal(t *testing.T) {
+	u, err := url.Parse("https://example.com/path?q=1")
+	require.NoError(t, err)
+	ratio := 0.5
+	cfg := marshalConfig{
+		Home:     "/home/user",
+		Debug:    true,
+		Hosts:    []string{"a", "b:c"},
+		Timeout:  90 * time.Second,
+		URL:      *u,
+		Started:  time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Labels:   map[string]string{"tier": "front", "app": "web"},
+		Matrix:   [][]int{{1, 2}, {3}},
+		Message:  "hello \"world\" # $HOME\nbye",
+		Ratio:    &ratio,
+		Inner:    marshalInner{Name: "inner"},
+		InnerPtr: &marshalInner{Name: "ptr"},
+		Ignored:  "ignored",
+		private:  "private",
+	}
+
+	out, err := Marshal(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, `HOME=/home/user
+PORT=3000
+DEBUG=true
+HOSTS="a:b\\:c"
+TIMEOUT=1m30s
+URL="https://example.com/path?q=1"
+STARTED=2020-01-02T03:04:05Z
+LABELS=app:web,tier:front
+MATRIX=1|2,3
+MESSAGE="hello \"world\" # \$HOME\nbye"
+RATIO=0.5
+INNER_NAME=inner
+PTR_NAME=ptr
+`, string(out))
+
+	vars, err := Parse(bytes.NewReader(out))
+	require.NoError(t, err)
+	parsed := marshalConfig{InnerPtr: &marshalInner{}}
+	require.NoError(t, env.Parse(&parsed, env.WithSource(vars)))
+	cfg.Port = 3000
+	cfg.Ignored = ""
+	cfg.private = ""
+	assert.Equal(t, cfg, parsed)
+}
+
+func TestMarshalErrors(t *testing.T) {
+	_, err := Marshal("nope")
+	assert.EqualError(t, err, "env: expected a struct or a pointer to a struct, got string")
+
+	type config struct {
+		Func func() `env:"FUNC"`
+	}
+	_, err = Marshal(config{Func: func() {}})
+	assert.EqualError(t, err, `env: field "Func": unsupported type func()`)
+}
+
+func TestMarshalSensitive(t *testing.T) {
+	type config struct {
+		User     string     `env:"USER"`
+		Password string     `env:"PASSWORD,sensitive"`
+		Token    env.Secret `env:"TOKEN"`
+	}
+	cfg := config{User: "admin", Password: "hunter2", Token: "t0ken"}
+
+	b, err := Marshal(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "USER=admin\nPASSWORD=*****\nTOKEN=*****\n", string(b))
+
+	b, err = MarshalCompose(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "USER=admin\nPASSWORD=*****\nTOKEN=*****\n", string(b))
+
+	b, err = Marshal(cfg, env.WithUnredacted())
+	require.NoError(t, err)
+	assert.Equal(t, "USER=admin\nPASSWORD=hunter2\nTOKEN=t0ken\n", string(b))
+
+	vars, err := ExampleVars(cfg)
+	require.NoError(t, err)
+	assert.True(t, vars[2].Sensitive)
+}
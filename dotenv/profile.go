@@ -0,0 +1,80 @@
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Options selects the .env files of a profile.
+type Options struct {
+	// Dir is the directory holding the files. Defaults to the current
+	// directory.
+	Dir string
+
+	// Profile is the name of the environment, e.g. "production". Defaults to
+	// the value of the variable named by ProfileVar.
+	Profile string
+
+	// ProfileVar is the variable read when Profile is empty. Defaults to
+	// APP_ENV.
+	ProfileVar string
+}
+
+// ProfileFiles returns the files of the profile selected by opts, from lowest
+// to highest precedence:
+//
+//	.env
+//	.env.local            (skipped for the "test" profile)
+//	.env.$APP_ENV
+//	.env.$APP_ENV.local
+//
+// so that local overrides win over shared files, and profile-specific files
+// win over generic ones. .env.local is skipped for tests so that they produce
+// the same results for everyone. The files are returned whether or not they
+// exist; LoadProfile and LoadAndParse ignore the missing ones.
+func ProfileFiles(opts Options) []string {
+	profile := opts.Profile
+	if profile == "" {
+		profileVar := opts.ProfileVar
+		if profileVar == "" {
+			profileVar = "APP_ENV"
+		}
+		profile = os.Getenv(profileVar)
+	}
+
+	names := []string{".env"}
+	if profile != "test" {
+		names = append(names, ".env.local")
+	}
+	if profile != "" {
+		names = append(names, ".env."+profile, ".env."+profile+".local")
+	}
+
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		paths = append(paths, filepath.Join(opts.Dir, name))
+	}
+	return paths
+}
+
+// LoadProfile loads the existing files of the profile selected by opts into
+// the process environment, like Load, later files taking precedence over
+// earlier ones.
+func LoadProfile(opts Options) error {
+	paths := existingFiles(ProfileFiles(opts))
+	if len(paths) == 0 {
+		return nil
+	}
+	return Load(paths...)
+}
+
+func existingFiles(paths []string) []string {
+	var existing []string
+	for _, path := range paths {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		existing = append(existing, path)
+	}
+	return existing
+}
@@ -0,0 +1,58 @@
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileFiles(t *testing.T) {
+	defer os.Clearenv()
+	assert.Equal(t, []string{".env", ".env.local"}, ProfileFiles(Options{}))
+
+	os.Setenv("APP_ENV", "production")
+	assert.Equal(t, []string{
+		filepath.Join("config", ".env"),
+		filepath.Join("config", ".env.local"),
+		filepath.Join("config", ".env.production"),
+		filepath.Join("config", ".env.production.local"),
+	}, ProfileFiles(Options{Dir: "config"}))
+
+	os.Setenv("GO_ENV", "dev")
+	assert.Equal(t, []string{".env", ".env.local", ".env.dev", ".env.dev.local"}, ProfileFiles(Options{ProfileVar: "GO_ENV"}))
+	assert.Equal(t, []string{".env", ".env.test", ".env.test.local"}, ProfileFiles(Options{Profile: "test"}))
+}
+
+func TestLoadProfile(t *testing.T) {
+	defer os.Clearenv()
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	write(".env", "A=env\nB=env\nC=env\nD=env\n")
+	write(".env.local", "B=local\nC=local\nD=local\n")
+	write(".env.staging", "C=staging\nD=staging\n")
+	write(".env.staging.local", "D=staging.local\n")
+
+	os.Setenv("APP_ENV", "staging")
+	require.NoError(t, LoadProfile(Options{Dir: dir}))
+	assert.Equal(t, "env", os.Getenv("A"))
+	assert.Equal(t, "local", os.Getenv("B"))
+	assert.Equal(t, "staging", os.Getenv("C"))
+	assert.Equal(t, "staging.local", os.Getenv("D"))
+
+	os.Clearenv()
+	type config struct {
+		A string `env:"A"`
+		B string `env:"B"`
+		C string `env:"C"`
+	}
+	var cfg config
+	require.NoError(t, LoadAndParse(&cfg, ProfileFiles(Options{Dir: dir, Profile: "test"})))
+	assert.Equal(t, config{A: "env", B: "env", C: "env"}, cfg)
+
+	require.NoError(t, LoadProfile(Options{Dir: t.TempDir()}))
+}
@@ -0,0 +1,62 @@
+package dotenv
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+)
+
+// Decryptor decrypts files encrypted with sops. The format is always
+// "dotenv".
+//
+// The sops Go package provides one without this package depending on it:
+//
+//	dotenv.RegisterDecryptor(dotenv.DecryptorFunc(decrypt.Data))
+type Decryptor interface {
+	Decrypt(data []byte, format string) ([]byte, error)
+}
+
+// DecryptorFunc adapts an ordinary function to the Decryptor interface.
+type DecryptorFunc func(data []byte, format string) ([]byte, error)
+
+// Decrypt calls f(data, format).
+func (f DecryptorFunc) Decrypt(data []byte, format string) ([]byte, error) {
+	return f(data, format)
+}
+
+// nolint: gochecknoglobals
+var (
+	decryptorMu sync.RWMutex
+	decryptor   Decryptor
+)
+
+// RegisterDecryptor sets the Decryptor used to read files encrypted with
+// sops, which are then read transparently by every function of this package.
+// Without one, reading an encrypted file fails.
+func RegisterDecryptor(d Decryptor) {
+	decryptorMu.Lock()
+	defer decryptorMu.Unlock()
+	decryptor = d
+}
+
+// isSOPS reports whether data is a .env file encrypted with sops, which
+// stores its metadata in sops_ prefixed keys.
+func isSOPS(data []byte) bool {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if bytes.HasPrefix(line, []byte("sops_mac=")) || bytes.HasPrefix(line, []byte("sops_version=")) {
+			return true
+		}
+	}
+	return false
+}
+
+func decrypt(data []byte) ([]byte, error) {
+	decryptorMu.RLock()
+	d := decryptor
+	decryptorMu.RUnlock()
+	if d == nil {
+		return nil, errors.New("file is encrypted with sops, but no Decryptor is registered")
+	}
+	return d.Decrypt(data, "dotenv")
+}
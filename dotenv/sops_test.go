@@ -0,0 +1,83 @@
+package dotenv
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const encrypted = `DB_PASSWORD=ENC[AES256_GCM,data:c2VjcmV0,type:str]
+PORT=ENC[AES256_GCM,data:ODA4MA==,type:str]
+sops_version=3.7.3
+sops_mac=ENC[AES256_GCM,data:bWFj,type:str]
+`
+
+// fakeDecrypt "decrypts" values by extracting their data, and drops the sops
+// metadata, like sops does.
+func fakeDecrypt(data []byte, format string) ([]byte, error) {
+	if format != "dotenv" {
+		return nil, errors.New("unexpected format " + format)
+	}
+	var out bytes.Buffer
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 || bytes.HasPrefix(line, []byte("sops_")) {
+			continue
+		}
+		kv := bytes.SplitN(line, []byte("="), 2)
+		value := bytes.TrimPrefix(kv[1], []byte("ENC[AES256_GCM,data:"))
+		value = bytes.TrimSuffix(value, []byte(",type:str]"))
+		decoded, err := base64.StdEncoding.DecodeString(string(value))
+		if err != nil {
+			return nil, err
+		}
+		out.Write(kv[0])
+		out.WriteByte('=')
+		out.Write(decoded)
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), nil
+}
+
+func TestSOPS(t *testing.T) {
+	defer RegisterDecryptor(nil)
+	path := writeFile(t, ".env.enc", encrypted)
+
+	_, err := Read(path)
+	assert.EqualError(t, err, "dotenv: "+path+": file is encrypted with sops, but no Decryptor is registered")
+
+	RegisterDecryptor(DecryptorFunc(fakeDecrypt))
+	vars, err := Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, Env{"DB_PASSWORD": "secret", "PORT": "8080"}, vars)
+
+	RegisterDecryptor(DecryptorFunc(func(data []byte, format string) ([]byte, error) {
+		return nil, errors.New("no key available")
+	}))
+	_, err = Read(path)
+	assert.EqualError(t, err, "dotenv: "+path+": no key available")
+}
+
+func TestSOPSLoadAndParse(t *testing.T) {
+	defer RegisterDecryptor(nil)
+	defer os.Clearenv()
+	RegisterDecryptor(DecryptorFunc(fakeDecrypt))
+	path := writeFile(t, ".env.enc", encrypted)
+
+	type config struct {
+		Password string `env:"DB_PASSWORD,required"`
+		Port     int    `env:"PORT"`
+	}
+	var cfg config
+	require.NoError(t, LoadAndParse(&cfg, []string{path}))
+	assert.Equal(t, config{Password: "secret", Port: 8080}, cfg)
+}
+
+func TestIsSOPS(t *testing.T) {
+	assert.True(t, isSOPS([]byte(encrypted)))
+	assert.False(t, isSOPS([]byte("A=1\nSOPS_VERSION=3\n")))
+}
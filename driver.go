@@ -0,0 +1,82 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// nolint: gochecknoglobals
+var (
+	driversMu sync.RWMutex
+	drivers   = map[reflect.Type]map[string]func() interface{}{}
+)
+
+// RegisterDriver registers a concrete configuration type for an interface.
+//
+// iface must be a nil pointer to the interface, e.g. (*Storage)(nil), and
+// factory must return a pointer to a struct implementing it. When Parse finds
+// a field of that interface type, it reads the field's `env` key as the
+// driver name, then parses the struct returned by factory using the field's
+// `envPrefix` followed by the upper-cased driver name and an underscore as
+// prefix, e.g. STORAGE_DRIVER=s3 reads STORAGE_S3_BUCKET. Parse returns an
+// error if factory returns nil.
+//
+// RegisterDriver panics if iface is not a pointer to an interface or if the
+// same name is registered twice for an interface.
+func RegisterDriver(iface interface{}, name string, factory func() interface{}) {
+	t := reflect.TypeOf(iface)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+		panic("env: RegisterDriver expects a pointer to an interface")
+	}
+	t = t.Elem()
+
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if drivers[t] == nil {
+		drivers[t] = map[string]func() interface{}{}
+	}
+	if _, dup := drivers[t][name]; dup {
+		panic(fmt.Sprintf("env: RegisterDriver called twice for driver %q of %s", name, t))
+	}
+	drivers[t][name] = factory
+}
+
+func lookupDriver(t reflect.Type, name string) (factory func() interface{}, registered bool) {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	byName, registered := drivers[t]
+	return byName[name], registered
+}
+
+func hasDrivers(t reflect.Type) bool {
+	_, registered := lookupDriver(t, "")
+	return registered
+}
+
+// parseDriver populates an interface field with the driver selected by its
+// `env` key.
+func (p *parser) parseDriver(prefix, path string, field reflect.Value, sf reflect.StructField) error {
+	name, _, err := p.get(prefix, path, sf)
+	if err != nil || name == "" {
+		return err
+	}
+	factory, _ := lookupDriver(sf.Type, name)
+	if factory == nil {
+		return p.reportError(path, fmt.Errorf(`env: unknown driver "%s" for field "%s" of type "%s"`, name, sf.Name, sf.Type))
+	}
+	cfg := factory()
+	if cfg == nil {
+		return p.reportError(path, fmt.Errorf(`env: driver "%s" for field "%s" of type "%s" returned nil`, name, sf.Name, sf.Type))
+	}
+	if !reflect.TypeOf(cfg).Implements(sf.Type) {
+		return p.reportError(path, fmt.Errorf(`env: driver "%s" of type "%T" does not implement "%s"`, name, cfg, sf.Type))
+	}
+	envPrefix := sf.Tag.Get("envPrefix") + strings.ToUpper(name) + "_"
+	if err := p.parsePrefix(prefix+envPrefix, path+".", cfg); err != nil {
+		return err
+	}
+	field.Set(reflect.ValueOf(cfg))
+	return nil
+}
@@ -0,0 +1,82 @@
+package env
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type storage interface {
+	Location() string
+}
+
+type s3Storage struct {
+	Bucket string `env:"BUCKET,required"`
+	Region string `env:"REGION" envDefault:"us-east-1"`
+}
+
+func (s *s3Storage) Location() string { return "s3://" + s.Bucket + "@" + s.Region }
+
+type diskStorage struct {
+	Path string `env:"PATH" envDefault:"/var/lib/app"`
+}
+
+func (d *diskStorage) Location() string { return "file://" + d.Path }
+
+type notStorage struct{}
+
+// nolint: gochecknoinits
+func init() {
+	RegisterDriver((*storage)(nil), "s3", func() interface{} { return &s3Storage{} })
+	RegisterDriver((*storage)(nil), "disk", func() interface{} { return &diskStorage{} })
+	RegisterDriver((*storage)(nil), "broken", func() interface{} { return &notStorage{} })
+	RegisterDriver((*storage)(nil), "none", func() interface{} { return nil })
+}
+
+type driverConfig struct {
+	Storage storage `env:"STORAGE_DRIVER" envPrefix:"STORAGE_" envDefault:"disk"`
+}
+
+func TestDriver(t *testing.T) {
+	defer os.Clearenv()
+	os.Setenv("APP_STORAGE_DRIVER", "s3")
+	os.Setenv("APP_STORAGE_S3_BUCKET", "uploads")
+
+	var cfg driverConfig
+	assert.NoError(t, ParsePrefix("APP_", &cfg))
+	assert.Equal(t, "s3://uploads@us-east-1", cfg.Storage.Location())
+}
+
+func TestDriverDefault(t *testing.T) {
+	defer os.Clearenv()
+	var cfg driverConfig
+	assert.NoError(t, Parse(&cfg))
+	assert.Equal(t, "file:///var/lib/app", cfg.Storage.Location())
+}
+
+func TestDriverErrors(t *testing.T) {
+	defer os.Clearenv()
+
+	os.Setenv("STORAGE_DRIVER", "gcs")
+	var cfg driverConfig
+	assert.EqualError(t, Parse(&cfg), `env: unknown driver "gcs" for field "Storage" of type "env.storage"`)
+
+	os.Setenv("STORAGE_DRIVER", "s3")
+	assert.EqualError(t, Parse(&cfg), `env: required environment variable "STORAGE_S3_BUCKET" is not set`)
+
+	os.Setenv("STORAGE_DRIVER", "broken")
+	assert.EqualError(t, Parse(&cfg), `env: driver "broken" of type "*env.notStorage" does not implement "env.storage"`)
+
+	os.Setenv("STORAGE_DRIVER", "none")
+	assert.EqualError(t, Parse(&cfg), `env: driver "none" for field "Storage" of type "env.storage" returned nil`)
+}
+
+func TestRegisterDriverPanics(t *testing.T) {
+	assert.Panics(t, func() {
+		RegisterDriver(storage(nil), "nil", func() interface{} { return nil })
+	})
+	assert.Panics(t, func() {
+		RegisterDriver((*storage)(nil), "s3", func() interface{} { return &s3Storage{} })
+	})
+}
@@ -0,0 +1,35 @@
+package env
+
+import "reflect"
+
+// WithDryRun makes Parse perform all lookups and parse every value, failing
+// as it would otherwise, but without modifying the structs it is given, nor
+// unsetting variables with WithUnset. Combined with WithReport, it tells
+// what a configuration would resolve to, e.g. for a `config check`
+// subcommand:
+//
+//	var report env.Report
+//	err := env.Parse(&config{}, env.WithDryRun(), env.WithReport(&report))
+//	for field, origin := range report.Provenance() {
+//		fmt.Println(field, origin)
+//	}
+func WithDryRun() Option {
+	return func(o *Options) {
+		o.DryRun = true
+	}
+}
+
+// dryRunTargets returns deep copies of the structs pointed to by vs, for
+// Parse to populate in their place.
+func dryRunTargets(vs []interface{}) []interface{} {
+	targets := make([]interface{}, len(vs))
+	for i, v := range vs {
+		ref := reflect.ValueOf(v)
+		if ref.Kind() != reflect.Ptr || ref.IsNil() {
+			targets[i] = v
+			continue
+		}
+		targets[i] = copyValue(ref).Interface()
+	}
+	return targets
+}
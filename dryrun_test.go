@@ -0,0 +1,38 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	type database struct {
+		URL string `env:"URL"`
+	}
+	type config struct {
+		Host     string            `env:"HOST"`
+		Port     int               `env:"PORT" envDefault:"8080"`
+		Hosts    []string          `env:"HOSTS"`
+		Bucket   Tracked[string]   `env:"BUCKET"`
+		Database *database         `envPrefix:"DB_"`
+		Labels   map[string]string `env:"LABELS"`
+	}
+	source := MapSource{"HOST": "localhost", "HOSTS": "a,b", "BUCKET": "b", "DB_URL": "postgres://", "LABELS": "a:1"}
+
+	cfg := config{Host: "before", Hosts: []string{"x"}, Database: &database{URL: "before"}}
+	var r Report
+	require.NoError(t, Parse(&cfg, WithSource(source), WithDryRun(), WithReport(&r), WithUnset()))
+	assert.Equal(t, config{Host: "before", Hosts: []string{"x"}, Database: &database{URL: "before"}}, cfg)
+	assert.Equal(t, "HOST from map", r.Provenance()["Host"])
+	assert.Equal(t, "DB_URL from map", r.Provenance()["Database.URL"])
+	assert.Len(t, source, 5)
+
+	source["PORT"] = "nope"
+	err := ParseAll([]interface{}{&cfg}, WithSource(source), WithDryRun())
+	assert.EqualError(t, err, `env: parse error on field "Port" of type "int" (PORT="nope"): strconv.ParseInt: parsing "nope": invalid syntax`)
+	assert.Equal(t, 0, cfg.Port)
+
+	assert.Equal(t, ErrNotAStructPtr, Parse(cfg, WithDryRun()))
+}
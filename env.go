@@ -1,16 +1,25 @@
+// Package env parses environment variables into structs, using field tags to
+// map each field to a variable.
+//
+// Parse and its variants are safe for concurrent use, as long as each call
+// gets a distinct target and any custom ParserFunc is itself safe for
+// concurrent use. Options and custom parsers only affect the call they are
+// given to.
 package env
 
 import (
+	"context"
 	"encoding"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/url"
-	"os"
 	"reflect"
 	"strconv"
 	"strings"
 	"time"
+	"unsafe"
 )
 
 // nolint: gochecknoglobals
@@ -95,27 +104,387 @@ var (
 // ParserFunc defines the signature of a function that can be used within `CustomParsers`
 type ParserFunc func(v string) (interface{}, error)
 
+// Options customizes the behaviour of Parse.
+type Options struct {
+	// Prefix is prepended to every key looked up in the environment.
+	Prefix string
+
+	// FuncMap holds custom parsers, keyed by the type they parse.
+	FuncMap map[reflect.Type]ParserFunc
+
+	// Strict makes Parse return an UnusedVarsError if any environment
+	// variable starting with Prefix was not consumed by a field.
+	Strict bool
+
+	// Unexported makes Parse also populate unexported fields.
+	Unexported bool
+
+	// Source provides the variables. Defaults to OSSource.
+	Source Source
+
+	// OnWarning is called with problems that do not make Parse fail.
+	OnWarning func(err error)
+
+	// RequiredAsWarning reports missing required variables through
+	// OnWarning instead of failing.
+	RequiredAsWarning bool
+
+	// StrictExpansion makes Parse fail when a field with the `envExpand`
+	// tag refers to a variable that is not set, instead of replacing the
+	// reference with an empty string.
+	StrictExpansion bool
+
+	// ExpansionDialect is the syntax of the references to variables in the
+	// values of fields with the `envExpand` tag.
+	ExpansionDialect ExpansionDialect
+
+	// ExpansionDepth, if positive, is the number of levels of references
+	// replaced in the values of the variables referred to by fields with
+	// the `envExpand` tag.
+	ExpansionDepth int
+
+	// TrimSpace makes Parse strip leading and trailing white space from
+	// every value, as the `trim` tag option does for a field.
+	TrimSpace bool
+
+	// Unquote makes Parse remove a pair of matching quotes surrounding
+	// every value, as the `unquote` tag option does for a field.
+	Unquote bool
+
+	// Policies constrain the values of the variables by name.
+	Policies []Policy
+
+	// NoDefaults makes Parse fail when a field falls back to its
+	// `envDefault` value, as if every field with a default was required.
+	NoDefaults bool
+
+	// Report, if not nil, is filled with how each field was resolved.
+	Report *Report
+
+	// Redactor masks the values of sensitive fields in reports, errors and
+	// the output of Marshal. Defaults to replacing them with *****.
+	Redactor func(field FieldParams, value string) string
+
+	// Unredacted makes Marshal write the values of sensitive fields as is.
+	Unredacted bool
+
+	// MaxValueLength, if positive, is the maximum length in bytes of a
+	// value, after expansion and loading files.
+	MaxValueLength int
+
+	// Concurrency is the maximum number of variables looked up at once in
+	// a ContextSource.
+	Concurrency int
+
+	// Retry controls how failed lookups in a ContextSource are retried.
+	Retry RetryPolicy
+
+	// Clock returns the current time, e.g. for fields with the `relative`
+	// tag option. Defaults to time.Now.
+	Clock func() time.Time
+
+	// PrefixExceptions lists keys that are looked up without Prefix, as if
+	// their fields were tagged with the `noprefix` option.
+	PrefixExceptions []string
+
+	// Unset makes Parse unset the variables it read once it succeeded.
+	Unset bool
+
+	// DryRun makes Parse leave its targets untouched.
+	DryRun bool
+
+	// WatchFiles makes Watch reload as soon as a file loaded by a field
+	// with the `file` option changes.
+	WatchFiles bool
+
+	// Validator, if not nil, is called with each struct given to Parse once
+	// it is populated, e.g. to check the constraints of validation tags.
+	Validator func(v interface{}) error
+
+	// RequireOneOf lists groups of fields, declared with the `envGroup` tag,
+	// of which at least one variable must be set.
+	RequireOneOf []string
+
+	// Exclusive lists groups of fields, declared with the `envGroup` tag, of
+	// which at most one variable may be set.
+	Exclusive []string
+
+	// ReloadPolicy says what Watch does when a reload fails.
+	ReloadPolicy ReloadPolicy
+
+	// OnReloadError is called by Watch with the errors of the reloads that
+	// failed. Defaults to OnWarning.
+	OnReloadError func(err error)
+
+	// environ holds the sorted keys of the Source used by strict mode,
+	// listed on first use unless shared by the caller.
+	environ environ
+}
+
+// Option is a function that changes Options.
+type Option func(*Options)
+
+// WithPrefix sets the prefix prepended to every key.
+func WithPrefix(prefix string) Option {
+	return func(o *Options) {
+		o.Prefix = prefix
+	}
+}
+
+// WithMaxValueLength makes Parse fail on values longer than n bytes, before
+// trying to parse them, e.g. to protect services from pathological values
+// produced by a broken deployment template.
+func WithMaxValueLength(n int) Option {
+	return func(o *Options) {
+		o.MaxValueLength = n
+	}
+}
+
+// WithPrefixExceptions makes the given keys be looked up without the prefix
+// set by WithPrefix, e.g. HOME rather than APP_HOME.
+func WithPrefixExceptions(keys ...string) Option {
+	return func(o *Options) {
+		o.PrefixExceptions = append(o.PrefixExceptions, keys...)
+	}
+}
+
+// WithFuncs adds custom parsers to the ones already configured.
+func WithFuncs(funcMap map[reflect.Type]ParserFunc) Option {
+	return func(o *Options) {
+		if o.FuncMap == nil {
+			o.FuncMap = map[reflect.Type]ParserFunc{}
+		}
+		for k, v := range funcMap {
+			o.FuncMap[k] = v
+		}
+	}
+}
+
+// WithStrict enables strict mode: once parsing is done, any environment
+// variable starting with the configured prefix that was not consumed by a
+// field is reported as an error. It has no effect without a prefix.
+func WithStrict() Option {
+	return func(o *Options) {
+		o.Strict = true
+	}
+}
+
+// WithUnexported makes Parse populate unexported fields as well, for
+// configuration structs that hide their raw values behind accessor methods.
+// Only use it on structs you own.
+func WithUnexported() Option {
+	return func(o *Options) {
+		o.Unexported = true
+	}
+}
+
+// WithWarningHook sets the function called with problems that do not make
+// Parse fail.
+func WithWarningHook(hook func(err error)) Option {
+	return func(o *Options) {
+		o.OnWarning = hook
+	}
+}
+
+// WithRequiredAsWarning makes missing required variables produce warnings,
+// passed to the warning hook, instead of errors. This eases migrations to
+// stricter configurations: every missing variable is reported while the
+// service keeps starting.
+func WithRequiredAsWarning() Option {
+	return func(o *Options) {
+		o.RequiredAsWarning = true
+	}
+}
+
+// WithStrictExpansion makes Parse fail when a field with the `envExpand` tag
+// refers to a variable that is neither set nor the key of a field with a
+// default, rather than silently replacing the reference with an empty
+// string, e.g. the host of a DSN.
+func WithStrictExpansion() Option {
+	return func(o *Options) {
+		o.StrictExpansion = true
+	}
+}
+
+// WithNoDefaults makes Parse fail when a variable is not set and its field
+// would fall back to its `envDefault` value, so that hardened deployments,
+// e.g. production ones, are guaranteed that every value was provided
+// explicitly. With WithRequiredAsWarning, the fields relying on defaults are
+// reported through the warning hook instead.
+func WithNoDefaults() Option {
+	return func(o *Options) {
+		o.NoDefaults = true
+	}
+}
+
 // Parse parses a struct containing `env` tags and loads its values from
 // environment variables.
-func Parse(v interface{}) error {
-	return ParseWithFuncs(v, map[reflect.Type]ParserFunc{})
+func Parse(v interface{}, opts ...Option) error {
+	return newParser(opts).parse(v)
+}
+
+// ParseAs parses a new T, which must be a struct, and returns it. On error,
+// it returns the zero T rather than a partially populated one.
+func ParseAs[T any](opts ...Option) (T, error) {
+	var v T
+	if err := Parse(&v, opts...); err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
+
+// ParseWithContext is like Parse, except that ctx bounds the resolution of
+// the variables: it is passed to the lookups of ContextSources, and Parse
+// stops with ctx's error, wrapped, once ctx is done, e.g. on shutdown.
+func ParseWithContext(ctx context.Context, v interface{}, opts ...Option) error {
+	p := newParser(opts)
+	p.ctx = ctx
+	return p.parse(v)
+}
+
+// ParseAll parses several structs with the same options, e.g. the
+// configurations of the components of an application sharing a prefix. In
+// strict mode a variable is only reported as unused if none of the structs
+// consumed it, and the environment is scanned once for all of them.
+func ParseAll(vs []interface{}, opts ...Option) error {
+	return newParser(opts).parse(vs...)
 }
 
 // ParsePrefix parses a struct containing `env` tags and loads its values from
 // environment variables. Prefixes evironment variables with prefix
-func ParsePrefix(prefix string, v interface{}) error {
-	return ParsePrefixWithFuncs(prefix, v, map[reflect.Type]ParserFunc{})
+func ParsePrefix(prefix string, v interface{}, opts ...Option) error {
+	return Parse(v, append([]Option{WithPrefix(prefix)}, opts...)...)
 }
 
 // ParseWithFuncs is the same as `Parse` except it also allows the user to pass
 // in custom parsers.
 func ParseWithFuncs(v interface{}, funcMap map[reflect.Type]ParserFunc) error {
-	return ParsePrefixWithFuncs("", v, funcMap)
+	return Parse(v, WithFuncs(funcMap))
 }
 
 // ParsePrefixWithFuncs is the same as `ParsePrefix` except it also allows the user to pass
 // in custom parsers.
 func ParsePrefixWithFuncs(prefix string, v interface{}, funcMap map[reflect.Type]ParserFunc) error {
+	return Parse(v, WithPrefix(prefix), WithFuncs(funcMap))
+}
+
+// parser holds the state of a single Parse call.
+type parser struct {
+	Options
+	ctx     context.Context
+	funcMap map[reflect.Type]ParserFunc
+	used    map[string]bool
+
+	// values holds the value of each variable resolved so far, defaults
+	// included, for expansions to refer to.
+	values    map[string]string
+	deferred  []*deferredField
+	expanding []string
+	expandErr error
+
+	// prefetched holds the lookups made concurrently before parsing.
+	prefetched map[string]prefetched
+
+	// files lists the files loaded by fields with the `file` option.
+	files []string
+
+	// afterParse holds the hooks to call once all fields are set.
+	afterParse []AfterParser
+
+	// parsed, if not nil, records the values parsed for each field, and
+	// cache holds the ones of a previous Parse, for Parser.Refresh.
+	parsed map[string]parsedValue
+	cache  map[string]parsedValue
+
+	// groups holds the keys of the fields of each group, and whether their
+	// variable is set.
+	groups map[string][]groupMember
+
+	// conditional holds the fields tagged with `envRequiredIf` whose
+	// variable is not set, to check once all the fields are parsed.
+	conditional []FieldParams
+
+	// lazy holds the keys of Lazy fields, which strict mode accepts
+	// although Parse does not read them.
+	lazy map[string]bool
+}
+
+func newParser(opts []Option) *parser {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return newParserWith(o)
+}
+
+// newParserWith is like newParser, with the options already applied.
+func newParserWith(o Options) *parser {
+	p := &parser{
+		Options: o,
+		ctx:     context.Background(),
+		funcMap: map[reflect.Type]ParserFunc{},
+		used:    map[string]bool{},
+		values:  map[string]string{},
+		lazy:    map[string]bool{},
+		groups:  map[string][]groupMember{},
+	}
+	if p.Source == nil {
+		p.Source = OSSource{}
+	}
+	for k, v := range defaultTypeParsers {
+		p.funcMap[k] = v
+	}
+	for k, v := range p.FuncMap {
+		p.funcMap[k] = v
+	}
+	return p
+}
+
+func (p *parser) parse(vs ...interface{}) (err error) {
+	if p.Report != nil {
+		*p.Report = Report{}
+		defer func(start time.Time) {
+			p.Report.Duration = time.Since(start)
+			p.Report.Err = err
+		}(time.Now())
+	}
+	if p.DryRun {
+		vs = dryRunTargets(vs)
+	}
+	p.prefetch(vs)
+	for _, v := range vs {
+		if err := p.parsePrefix(p.Prefix, "", v); err != nil {
+			return err
+		}
+	}
+	if err := p.resolveDeferred(); err != nil {
+		return err
+	}
+	if err := p.checkGroups(); err != nil {
+		return err
+	}
+	if err := p.checkConditions(); err != nil {
+		return err
+	}
+	if err := p.runHooks(); err != nil {
+		return err
+	}
+	if err := p.validate(vs); err != nil {
+		return err
+	}
+	if err := p.checkUnused(); err != nil {
+		return err
+	}
+	if p.Unset && !p.DryRun {
+		return p.unsetUsed()
+	}
+	return nil
+}
+
+// parsePrefix parses the struct pointed to by v. path is the path of the
+// struct's fields, e.g. "Inner." for a field named Inner, used in reports.
+func (p *parser) parsePrefix(prefix, path string, v interface{}) error {
 	ptrRef := reflect.ValueOf(v)
 	if ptrRef.Kind() != reflect.Ptr {
 		return ErrNotAStructPtr
@@ -124,24 +493,24 @@ func ParsePrefixWithFuncs(prefix string, v interface{}, funcMap map[reflect.Type
 	if ref.Kind() != reflect.Struct {
 		return ErrNotAStructPtr
 	}
-	var parsers = defaultTypeParsers
-	for k, v := range funcMap {
-		parsers[k] = v
-	}
-	return doParse(prefix, ref, parsers)
+	return p.doParse(prefix, path, ref)
 }
 
-func doParse(prefix string, ref reflect.Value, funcMap map[reflect.Type]ParserFunc) error {
+func (p *parser) doParse(prefix, path string, ref reflect.Value) error {
 	var refType = ref.Type()
+	setDefaults(ref)
 
 	for i := 0; i < refType.NumField(); i++ {
 		refField := ref.Field(i)
 		if !refField.CanSet() {
-			continue
+			if !p.Unexported || !refField.CanAddr() {
+				continue
+			}
+			refField = reflect.NewAt(refField.Type(), unsafe.Pointer(refField.UnsafeAddr())).Elem()
 		}
 		if reflect.Ptr == refField.Kind() && !refField.IsNil() {
 			envPrefix := refType.Field(i).Tag.Get("envPrefix")
-			err := ParsePrefixWithFuncs(prefix+envPrefix, refField.Interface(), funcMap)
+			err := p.parsePrefix(prefix+envPrefix, path+refType.Field(i).Name+".", refField.Interface())
 			if err != nil {
 				return err
 			}
@@ -149,74 +518,234 @@ func doParse(prefix string, ref reflect.Value, funcMap map[reflect.Type]ParserFu
 		}
 		if reflect.Struct == refField.Kind() && refField.CanAddr() && refField.Type().Name() == "" {
 			envPrefix := refType.Field(i).Tag.Get("envPrefix")
-			err := ParsePrefix(prefix+envPrefix, refField.Addr().Interface())
+			err := p.parsePrefix(prefix+envPrefix, path+refType.Field(i).Name+".", refField.Addr().Interface())
 			if err != nil {
 				return err
 			}
 			continue
 		}
 		refTypeField := refType.Field(i)
-		value, err := get(prefix, refTypeField)
-		if err != nil {
-			return err
-		}
-		if value == "" {
-			if reflect.Struct == refField.Kind() {
-				envPrefix := refType.Field(i).Tag.Get("envPrefix")
-				if err := doParse(prefix+envPrefix, refField, funcMap); err != nil {
-					return err
-				}
-			}
+		if strings.EqualFold(refTypeField.Tag.Get("envExpand"), "true") {
+			p.deferField(prefix, path+refTypeField.Name, refField, refTypeField)
 			continue
 		}
-		if err := set(refField, refTypeField, value, funcMap); err != nil {
+		if err := p.parseField(prefix, path+refTypeField.Name, refField, refTypeField); err != nil {
 			return err
 		}
 	}
+	p.addHooks(ref)
 	return nil
 }
 
-func get(prefix string, field reflect.StructField) (val string, err error) {
-	var required bool
-	var exists bool
-	var loadFile bool
-	var expand = strings.EqualFold(field.Tag.Get("envExpand"), "true")
+// parseField parses a single field, path being its full path, e.g.
+// "Inner.Name".
+func (p *parser) parseField(prefix, path string, refField reflect.Value, refTypeField reflect.StructField) error {
+	if tr := asTracker(refField); tr != nil {
+		return p.parseTracked(prefix, path, tr, refTypeField)
+	}
+	if lz := asLazy(refField); lz != nil {
+		return p.parseLazy(prefix, path, lz, refTypeField)
+	}
+	if reflect.Interface == refField.Kind() && hasDrivers(refField.Type()) {
+		return p.parseDriver(prefix, path, refField, refTypeField)
+	}
+	value, _, err := p.get(prefix, path, refTypeField)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		if reflect.Struct == refField.Kind() {
+			envPrefix := refTypeField.Tag.Get("envPrefix")
+			return p.doParse(prefix+envPrefix, path+".", refField)
+		}
+		return nil
+	}
+	// relative values depend on the clock as well as on value, so they are
+	// never taken from the cache
+	if p.parsed == nil || hasOption(refTypeField, "relative") {
+		return p.setField(prefix, path, refField, refTypeField, value)
+	}
+	if c, ok := p.cache[path]; ok && c.raw == value && c.value.Type() == refField.Type() {
+		refField.Set(copyValue(c.value))
+	} else if err := p.setField(prefix, path, refField, refTypeField, value); err != nil {
+		return err
+	}
+	p.remember(path, value, refField)
+	return nil
+}
 
-	key, opts := parseKeyForOption(field.Tag.Get("env"))
+func (p *parser) warn(err error) {
+	if p.OnWarning != nil {
+		p.OnWarning(err)
+	}
+}
 
-	for _, opt := range opts {
-		switch opt {
-		case "":
-			break
-		case "file":
-			loadFile = true
-		case "required":
-			required = true
-		default:
-			return "", fmt.Errorf("env: tag option %q not supported", opt)
+// checkUnused returns an UnusedVarsError listing the environment variables
+// under the configured prefix that no field consumed, if strict mode is on.
+func (p *parser) checkUnused() error {
+	if !p.Strict || p.Prefix == "" {
+		return nil
+	}
+	if p.environ == nil {
+		keys, err := Keys(p.Prefix, p.Source)
+		if err != nil {
+			return err
+		}
+		p.environ = keys
+	}
+	var unused []string
+	for _, key := range p.environ.withPrefix(p.Prefix) {
+		if !p.used[key] && !p.lazy[key] {
+			unused = append(unused, key)
+		}
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+	return UnusedVarsError{Prefix: p.Prefix, Keys: unused}
+}
+
+// get looks up the value of field, and records the outcome in the report, if
+// any.
+func (p *parser) get(prefix, path string, field reflect.StructField) (val string, origin Origin, err error) {
+	params, err := p.fieldParams(prefix, path, field)
+	if err != nil {
+		return "", "", err
+	}
+	if p.Report == nil || params.Key == "" {
+		val, prov, err := p.lookup(params)
+		return val, prov.origin, err
+	}
+	start := time.Now()
+	val, prov, err := p.lookup(params)
+	p.Report.add(FieldReport{
+		Field:     path,
+		Key:       params.Key,
+		Origin:    prov.origin,
+		Source:    prov.sourceName(),
+		File:      prov.file,
+		Refs:      prov.refs,
+		Value:     val,
+		Sensitive: params.Sensitive,
+		Duration:  time.Since(start),
+		Err:       err,
+		redacted:  p.redact(params, val),
+	})
+	return val, prov.origin, err
+}
+
+func (p *parser) lookup(params FieldParams) (val string, prov provenance, err error) {
+	if err := p.ctx.Err(); err != nil {
+		return "", prov, fmt.Errorf("env: %w", err)
+	}
+	var exists bool
+	if params.Key == "" {
+		val = params.DefaultValue
+	} else if params.Chunked {
+		if val, exists, prov.source, err = p.lookupChunks(params.Key); err != nil {
+			return "", prov, err
+		}
+	} else if val, exists, prov.source, err = p.lookupFrom(params.Key); err != nil {
+		return "", prov, err
+	}
+	if params.Key != "" {
+		p.used[params.Key] = true
+	}
+	p.addToGroups(params, exists)
+	if params.RequiredIfKey != "" && !exists {
+		p.conditional = append(p.conditional, params)
+	}
+	if exists {
+		prov.origin = OriginEnv
+	} else {
+		val, prov.source = params.DefaultValue, nil
+		if params.HasDefaultValue {
+			prov.origin = OriginDefault
 		}
 	}
 
-	defaultValue := field.Tag.Get("envDefault")
-	val, exists = getOr(prefix, key, defaultValue)
+	if params.Expand {
+		if val, err = p.expandValue(params, val, &prov.refs); err != nil {
+			return "", prov, err
+		}
+	}
+	if exists || params.HasDefaultValue {
+		p.values[params.Key] = val
+	}
 
-	if expand {
-		val = os.ExpandEnv(val)
+	if params.Required && !exists {
+		err := fmt.Errorf(`env: required environment variable %q is not set`, params.Key)
+		if suggestion := p.suggest(params.Key); suggestion != "" {
+			err = fmt.Errorf(`env: required environment variable %q is not set, did you mean %q?`, params.Key, suggestion)
+		}
+		if !p.RequiredAsWarning {
+			return "", prov, err
+		}
+		p.warn(err)
 	}
 
-	if required && !exists {
-		return "", fmt.Errorf(`env: required environment variable %q is not set`, key)
+	if p.NoDefaults && prov.origin == OriginDefault && params.Key != "" {
+		err := fmt.Errorf(`env: environment variable %q is not set, and defaults are not allowed`, params.Key)
+		if !p.RequiredAsWarning {
+			return "", prov, err
+		}
+		p.warn(err)
 	}
 
-	if loadFile && val != "" {
+	if params.LoadFile && val != "" {
+		if err := p.ctx.Err(); err != nil {
+			return "", prov, fmt.Errorf("env: %w", err)
+		}
 		filename := val
+		prov.file = filename
+		p.files = append(p.files, filename)
 		val, err = getFromFile(filename)
 		if err != nil {
-			return "", fmt.Errorf(`env: could not load content of file "%s" from variable %s: %v`, filename, key, err)
+			return "", prov, fmt.Errorf(`env: could not load content of file "%s" from variable %s: %v`, filename, params.OwnKey, err)
 		}
 	}
 
-	return val, err
+	if val, err = p.transform(params, val); err != nil {
+		return "", prov, err
+	}
+
+	if p.MaxValueLength > 0 && len(val) > p.MaxValueLength {
+		return "", prov, fmt.Errorf(`env: value of environment variable %q is too long: %d bytes, the limit is %d`, params.Key, len(val), p.MaxValueLength)
+	}
+
+	if params.Key != "" && (exists || params.HasDefaultValue) {
+		if err := p.checkPolicies(params, val); err != nil {
+			return "", prov, err
+		}
+	}
+
+	return val, prov, err
+}
+
+// setField parses value into field, redacting the value from errors if the
+// field is sensitive.
+func (p *parser) setField(prefix, path string, field reflect.Value, sf reflect.StructField, value string) error {
+	var err error
+	if hasOption(sf, "relative") {
+		err = p.setRelative(field, sf, value)
+	} else {
+		err = set(field, sf, value, p.funcMap)
+	}
+	if err == nil {
+		return nil
+	}
+	params, _ := p.fieldParams(prefix, path, sf)
+	if pe, ok := err.(parseError); ok {
+		pe.key, pe.value = params.Key, value
+		if params.Sensitive {
+			pe.value = p.redact(params, value)
+			pe.err = redactError(pe.err, sensitiveParts(sf, value), pe.value)
+		}
+		err = pe
+	} else if params.Sensitive {
+		err = redactError(err, sensitiveParts(sf, value), p.redact(params, value))
+	}
+	return p.reportError(path, err)
 }
 
 // split the env tag's key into the expected key and desired option, if any.
@@ -225,23 +754,59 @@ func parseKeyForOption(key string) (string, []string) {
 	return opts[0], opts[1:]
 }
 
+// hasOption reports whether the env tag of sf contains option.
+func hasOption(sf reflect.StructField, option string) bool {
+	_, opts := parseKeyForOption(sf.Tag.Get("env"))
+	for _, opt := range opts {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
 func getFromFile(filename string) (value string, err error) {
 	b, err := ioutil.ReadFile(filename)
 	return string(b), err
 }
 
-func getOr(prefix, key, defaultValue string) (value string, exists bool) {
-	value, exists = os.LookupEnv(prefix + key)
-	if !exists {
-		value = defaultValue
+// lookupSource looks key up in the Source.
+func (p *parser) lookupSource(key string) (string, bool, error) {
+	value, exists, _, err := p.lookupFrom(key)
+	return value, exists, err
+}
+
+// lookupFrom is like lookupSource, except that it also returns the Source
+// that supplied the value, which is a member of the Source if it was built
+// with ChainSource.
+func (p *parser) lookupFrom(key string) (string, bool, Source, error) {
+	cs, ok := p.Source.(ContextSource)
+	if !ok {
+		value, exists := p.Source.Lookup(key)
+		return value, exists, p.Source, nil
+	}
+	var value string
+	var exists bool
+	var from Source
+	var err error
+	if r, ok := p.prefetched[key]; ok {
+		value, exists, from, err = r.value, r.exists, r.source, r.err
+	} else {
+		value, exists, from, err = p.lookupRetry(cs, key)
+	}
+	if err != nil {
+		return "", false, nil, fmt.Errorf("env: could not look up %q: %w", key, err)
 	}
-	return value, exists
+	return value, exists, from, nil
 }
 
 func set(field reflect.Value, sf reflect.StructField, value string, funcMap map[reflect.Type]ParserFunc) error {
 	if field.Kind() == reflect.Slice {
 		return handleSlice(field, value, sf, funcMap)
 	}
+	if field.Kind() == reflect.Map {
+		return handleMap(field, value, sf, funcMap)
+	}
 
 	var tm = asTextUnmarshaler(field)
 	if tm != nil {
@@ -274,7 +839,11 @@ func set(field reflect.Value, sf reflect.StructField, value string, funcMap map[
 			return newParseError(sf, err)
 		}
 
-		fieldee.Set(reflect.ValueOf(val).Convert(typee))
+		var v = reflect.ValueOf(val).Convert(typee)
+		if err := checkAlias(typee, v); err != nil {
+			return newParseError(sf, err)
+		}
+		fieldee.Set(v)
 		return nil
 	}
 
@@ -286,42 +855,193 @@ func handleSlice(field reflect.Value, value string, sf reflect.StructField, func
 	if separator == "" {
 		separator = ","
 	}
-	var parts = strings.Split(value, separator)
-
-	var typee = sf.Type.Elem()
-	if typee.Kind() == reflect.Ptr {
-		typee = typee.Elem()
-	}
-
-	if _, ok := reflect.New(typee).Interface().(encoding.TextUnmarshaler); ok {
-		return parseTextUnmarshalers(field, parts, sf)
+	parts, err := splitElements(sf, value, separator)
+	if err != nil {
+		return newParseError(sf, err)
 	}
 
-	parserFunc, ok := funcMap[typee]
-	if !ok {
-		parserFunc, ok = defaultBuiltInParsers[typee.Kind()]
-		if !ok {
-			return newNoParserError(sf)
+	if sf.Type.Elem().Kind() != reflect.Slice {
+		result, err := parseSlice(sf.Type, parts, sf, funcMap)
+		if err != nil {
+			return err
 		}
+		field.Set(result)
+		return nil
 	}
 
+	var innerSeparator = sf.Tag.Get("envInnerSeparator")
+	if innerSeparator == "" {
+		innerSeparator = "|"
+	}
 	var result = reflect.MakeSlice(sf.Type, 0, len(parts))
 	for _, part := range parts {
-		r, err := parserFunc(part)
+		innerParts, err := splitElements(sf, part, innerSeparator)
 		if err != nil {
 			return newParseError(sf, err)
 		}
-		var v = reflect.ValueOf(r).Convert(typee)
-		if sf.Type.Elem().Kind() == reflect.Ptr {
-			v = reflect.New(typee)
-			v.Elem().Set(reflect.ValueOf(r).Convert(typee))
+		inner, err := parseSlice(sf.Type.Elem(), innerParts, sf, funcMap)
+		if err != nil {
+			return err
+		}
+		result = reflect.Append(result, inner)
+	}
+	field.Set(result)
+	return nil
+}
+
+// splitElements splits value into the elements of the slice field sf, as
+// a JSON array or shell words if sf has the jsonArray or shellWords option,
+// or around separator otherwise.
+func splitElements(sf reflect.StructField, value, separator string) ([]string, error) {
+	if hasOption(sf, "jsonArray") {
+		return splitJSONArray(value)
+	}
+	if hasOption(sf, "shellWords") {
+		return splitShellWords(value)
+	}
+	return splitEscaped(value, separator), nil
+}
+
+// parseSlice parses each of the parts into an element of a new slice of
+// type sliceType.
+func parseSlice(sliceType reflect.Type, parts []string, sf reflect.StructField, funcMap map[reflect.Type]ParserFunc) (reflect.Value, error) {
+	var result = reflect.MakeSlice(sliceType, 0, len(parts))
+	for _, part := range parts {
+		v, err := parseValue(sliceType.Elem(), part, sf, funcMap)
+		if err != nil {
+			return reflect.Value{}, err
 		}
 		result = reflect.Append(result, v)
 	}
+	return result, nil
+}
+
+// parseValue parses a single slice element or map key or value of type typ.
+func parseValue(typ reflect.Type, value string, sf reflect.StructField, funcMap map[reflect.Type]ParserFunc) (reflect.Value, error) {
+	var typee = typ
+	if typee.Kind() == reflect.Ptr {
+		typee = typee.Elem()
+	}
+
+	var v reflect.Value
+	if tm, ok := reflect.New(typee).Interface().(encoding.TextUnmarshaler); ok {
+		if err := tm.UnmarshalText([]byte(value)); err != nil {
+			return reflect.Value{}, newParseError(sf, err)
+		}
+		v = reflect.ValueOf(tm).Elem()
+	} else if parserFunc, ok := funcMap[typee]; ok {
+		r, err := parserFunc(value)
+		if err != nil {
+			return reflect.Value{}, newParseError(sf, err)
+		}
+		v = reflect.ValueOf(r).Convert(typee)
+	} else if parserFunc, ok := defaultBuiltInParsers[typee.Kind()]; ok {
+		r, err := parserFunc(value)
+		if err != nil {
+			return reflect.Value{}, newParseError(sf, err)
+		}
+		v = reflect.ValueOf(r).Convert(typee)
+		if err := checkAlias(typee, v); err != nil {
+			return reflect.Value{}, newParseError(sf, err)
+		}
+	} else {
+		return reflect.Value{}, newNoParserError(sf)
+	}
+
+	if typ.Kind() == reflect.Ptr {
+		ptr := reflect.New(typee)
+		ptr.Elem().Set(v)
+		return ptr, nil
+	}
+	return v, nil
+}
+
+func handleMap(field reflect.Value, value string, sf reflect.StructField, funcMap map[reflect.Type]ParserFunc) error {
+	var separator = sf.Tag.Get("envSeparator")
+	if separator == "" {
+		separator = ","
+	}
+	var keyValSeparator = sf.Tag.Get("envKeyValSeparator")
+	if keyValSeparator == "" {
+		keyValSeparator = ":"
+	}
+	var innerSeparator = sf.Tag.Get("envInnerSeparator")
+	if innerSeparator == "" {
+		innerSeparator = "|"
+	}
+
+	var result = reflect.MakeMap(sf.Type)
+	for _, part := range splitEscaped(value, separator) {
+		pair := strings.SplitN(part, keyValSeparator, 2)
+		if len(pair) != 2 {
+			return newParseError(sf, fmt.Errorf(`invalid map item: "%s"`, part))
+		}
+		k, err := parseValue(sf.Type.Key(), pair[0], sf, funcMap)
+		if err != nil {
+			return err
+		}
+		var v reflect.Value
+		if sf.Type.Elem().Kind() == reflect.Slice {
+			v, err = parseSlice(sf.Type.Elem(), splitEscaped(pair[1], innerSeparator), sf, funcMap)
+		} else {
+			v, err = parseValue(sf.Type.Elem(), pair[1], sf, funcMap)
+		}
+		if err != nil {
+			return err
+		}
+		result.SetMapIndex(k, v)
+	}
 	field.Set(result)
 	return nil
 }
 
+// splitJSONArray splits a JSON array into its elements. String elements are
+// unquoted, other elements are kept as raw JSON.
+func splitJSONArray(value string) ([]string, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON array: %v", err)
+	}
+	var parts = make([]string, 0, len(raw))
+	for _, r := range raw {
+		var s string
+		if err := json.Unmarshal(r, &s); err != nil {
+			s = string(r)
+		}
+		parts = append(parts, s)
+	}
+	return parts, nil
+}
+
+// splitEscaped splits value around each instance of separator, except those
+// preceded by a backslash, which are kept (without the backslash) as part of
+// the element. Two backslashes are read as one, so that elements can end
+// with a backslash; other backslashes are kept as they are.
+func splitEscaped(value, separator string) []string {
+	var parts []string
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && strings.HasPrefix(value[i+1:], `\`) {
+			b.WriteByte('\\')
+			i++
+			continue
+		}
+		if value[i] == '\\' && strings.HasPrefix(value[i+1:], separator) {
+			b.WriteString(separator)
+			i += len(separator)
+			continue
+		}
+		if strings.HasPrefix(value[i:], separator) {
+			parts = append(parts, b.String())
+			b.Reset()
+			i += len(separator) - 1
+			continue
+		}
+		b.WriteByte(value[i])
+	}
+	return append(parts, b.String())
+}
+
 func asTextUnmarshaler(field reflect.Value) encoding.TextUnmarshaler {
 	if reflect.Ptr == field.Kind() {
 		if field.IsNil() {
@@ -338,32 +1058,6 @@ func asTextUnmarshaler(field reflect.Value) encoding.TextUnmarshaler {
 	return tm
 }
 
-func parseTextUnmarshalers(field reflect.Value, data []string, sf reflect.StructField) error {
-	s := len(data)
-	elemType := field.Type().Elem()
-	slice := reflect.MakeSlice(reflect.SliceOf(elemType), s, s)
-	for i, v := range data {
-		sv := slice.Index(i)
-		kind := sv.Kind()
-		if kind == reflect.Ptr {
-			sv = reflect.New(elemType.Elem())
-		} else {
-			sv = sv.Addr()
-		}
-		tm := sv.Interface().(encoding.TextUnmarshaler)
-		if err := tm.UnmarshalText([]byte(v)); err != nil {
-			return newParseError(sf, err)
-		}
-		if kind == reflect.Ptr {
-			slice.Index(i).Set(sv)
-		}
-	}
-
-	field.Set(slice)
-
-	return nil
-}
-
 func newParseError(sf reflect.StructField, err error) error {
 	if err == nil {
 		return nil
@@ -377,12 +1071,38 @@ func newParseError(sf reflect.StructField, err error) error {
 type parseError struct {
 	sf  reflect.StructField
 	err error
+
+	// key and value are the variable the field was parsed from, and its
+	// value, redacted for sensitive fields, once known.
+	key, value string
 }
 
+// maxErrorValueLength is the length beyond which values are truncated in
+// parse errors.
+const maxErrorValueLength = 64
+
 func (e parseError) Error() string {
-	return fmt.Sprintf(`env: parse error on field "%s" of type "%s": %v`, e.sf.Name, e.sf.Type, e.err)
+	if e.key == "" {
+		return fmt.Sprintf(`env: parse error on field "%s" of type "%s": %v`, e.sf.Name, e.sf.Type, e.err)
+	}
+	value := e.value
+	if len(value) > maxErrorValueLength {
+		value = value[:maxErrorValueLength] + "..."
+	}
+	return fmt.Sprintf(`env: parse error on field "%s" of type "%s" (%s=%q): %v`, e.sf.Name, e.sf.Type, e.key, value, e.err)
 }
 
 func newNoParserError(sf reflect.StructField) error {
 	return fmt.Errorf(`env: no parser found for field "%s" of type "%s"`, sf.Name, sf.Type)
 }
+
+// UnusedVarsError is returned in strict mode when environment variables
+// under the configured prefix were not consumed by any field.
+type UnusedVarsError struct {
+	Prefix string
+	Keys   []string
+}
+
+func (e UnusedVarsError) Error() string {
+	return fmt.Sprintf(`env: unknown environment variables with prefix "%s": %s`, e.Prefix, strings.Join(e.Keys, ", "))
+}
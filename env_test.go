@@ -1,12 +1,14 @@
 package env
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
@@ -408,7 +410,7 @@ func TestParsesEnvInnerFails(t *testing.T) {
 	}
 	os.Setenv("NUMBER", "not-a-number")
 	var cfg = config{}
-	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Number\" of type \"int\": strconv.ParseInt: parsing \"not-a-number\": invalid syntax")
+	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Number\" of type \"int\" (NUMBER=\"not-a-number\"): strconv.ParseInt: parsing \"not-a-number\": invalid syntax")
 }
 
 func TestParsesEnvInnerNil(t *testing.T) {
@@ -424,7 +426,7 @@ func TestParsesEnvInnerInvalid(t *testing.T) {
 	cfg := ParentStruct{
 		InnerStruct: &InnerStruct{},
 	}
-	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Number\" of type \"uint\": strconv.ParseUint: parsing \"-547\": invalid syntax")
+	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Number\" of type \"uint\" (innernum=\"-547\"): strconv.ParseUint: parsing \"-547\": invalid syntax")
 }
 
 func TestParsesEnvNested(t *testing.T) {
@@ -466,7 +468,7 @@ func TestInvalidBool(t *testing.T) {
 	defer os.Clearenv()
 
 	cfg := Config{}
-	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Bool\" of type \"bool\": strconv.ParseBool: parsing \"should-be-a-bool\": invalid syntax")
+	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Bool\" of type \"bool\" (BOOL=\"should-be-a-bool\"): strconv.ParseBool: parsing \"should-be-a-bool\": invalid syntax")
 }
 
 func TestInvalidInt(t *testing.T) {
@@ -474,7 +476,7 @@ func TestInvalidInt(t *testing.T) {
 	defer os.Clearenv()
 
 	cfg := Config{}
-	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Int\" of type \"int\": strconv.ParseInt: parsing \"should-be-an-int\": invalid syntax")
+	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Int\" of type \"int\" (INT=\"should-be-an-int\"): strconv.ParseInt: parsing \"should-be-an-int\": invalid syntax")
 }
 
 func TestInvalidUint(t *testing.T) {
@@ -482,7 +484,7 @@ func TestInvalidUint(t *testing.T) {
 	defer os.Clearenv()
 
 	cfg := Config{}
-	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Uint\" of type \"uint\": strconv.ParseUint: parsing \"-44\": invalid syntax")
+	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Uint\" of type \"uint\" (UINT=\"-44\"): strconv.ParseUint: parsing \"-44\": invalid syntax")
 }
 
 func TestInvalidFloat32(t *testing.T) {
@@ -490,7 +492,7 @@ func TestInvalidFloat32(t *testing.T) {
 	defer os.Clearenv()
 
 	cfg := Config{}
-	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Float32\" of type \"float32\": strconv.ParseFloat: parsing \"AAA\": invalid syntax")
+	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Float32\" of type \"float32\" (FLOAT32=\"AAA\"): strconv.ParseFloat: parsing \"AAA\": invalid syntax")
 }
 
 func TestInvalidFloat64(t *testing.T) {
@@ -498,7 +500,7 @@ func TestInvalidFloat64(t *testing.T) {
 	defer os.Clearenv()
 
 	cfg := Config{}
-	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Float64\" of type \"float64\": strconv.ParseFloat: parsing \"AAA\": invalid syntax")
+	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Float64\" of type \"float64\" (FLOAT64=\"AAA\"): strconv.ParseFloat: parsing \"AAA\": invalid syntax")
 }
 
 func TestInvalidUint64(t *testing.T) {
@@ -506,7 +508,7 @@ func TestInvalidUint64(t *testing.T) {
 	defer os.Clearenv()
 
 	cfg := Config{}
-	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Uint64\" of type \"uint64\": strconv.ParseUint: parsing \"AAA\": invalid syntax")
+	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Uint64\" of type \"uint64\" (UINT64=\"AAA\"): strconv.ParseUint: parsing \"AAA\": invalid syntax")
 }
 
 func TestInvalidInt64(t *testing.T) {
@@ -514,7 +516,16 @@ func TestInvalidInt64(t *testing.T) {
 	defer os.Clearenv()
 
 	cfg := Config{}
-	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Int64\" of type \"int64\": strconv.ParseInt: parsing \"AAA\": invalid syntax")
+	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Int64\" of type \"int64\" (INT64=\"AAA\"): strconv.ParseInt: parsing \"AAA\": invalid syntax")
+}
+
+func TestParseErrorLongValue(t *testing.T) {
+	type config struct {
+		Ports []int `env:"PORTS"`
+	}
+	value := strings.Repeat("80,", 30) + "http"
+	err := Parse(&config{}, WithSource(MapSource{"PORTS": value}))
+	assert.EqualError(t, err, `env: parse error on field "Ports" of type "[]int" (PORTS="`+value[:64]+`..."): strconv.ParseInt: parsing "http": invalid syntax`)
 }
 
 func TestInvalidInt64Slice(t *testing.T) {
@@ -524,7 +535,7 @@ func TestInvalidInt64Slice(t *testing.T) {
 
 	os.Setenv("BADINTS", "A,2,3")
 	cfg := &config{}
-	assert.EqualError(t, Parse(cfg), "env: parse error on field \"BadFloats\" of type \"[]int64\": strconv.ParseInt: parsing \"A\": invalid syntax")
+	assert.EqualError(t, Parse(cfg), "env: parse error on field \"BadFloats\" of type \"[]int64\" (BADINTS=\"A,2,3\"): strconv.ParseInt: parsing \"A\": invalid syntax")
 }
 
 func TestInvalidUInt64Slice(t *testing.T) {
@@ -534,7 +545,7 @@ func TestInvalidUInt64Slice(t *testing.T) {
 
 	os.Setenv("BADFLOATS", "A,2,3")
 	cfg := &config{}
-	assert.EqualError(t, Parse(cfg), "env: parse error on field \"BadFloats\" of type \"[]uint64\": strconv.ParseUint: parsing \"A\": invalid syntax")
+	assert.EqualError(t, Parse(cfg), "env: parse error on field \"BadFloats\" of type \"[]uint64\" (BADINTS=\"A,2,3\"): strconv.ParseUint: parsing \"A\": invalid syntax")
 }
 
 func TestInvalidFloat32Slice(t *testing.T) {
@@ -544,7 +555,7 @@ func TestInvalidFloat32Slice(t *testing.T) {
 
 	os.Setenv("BADFLOATS", "A,2.0,3.0")
 	cfg := &config{}
-	assert.EqualError(t, Parse(cfg), "env: parse error on field \"BadFloats\" of type \"[]float32\": strconv.ParseFloat: parsing \"A\": invalid syntax")
+	assert.EqualError(t, Parse(cfg), "env: parse error on field \"BadFloats\" of type \"[]float32\" (BADFLOATS=\"A,2.0,3.0\"): strconv.ParseFloat: parsing \"A\": invalid syntax")
 }
 
 func TestInvalidFloat64Slice(t *testing.T) {
@@ -554,7 +565,7 @@ func TestInvalidFloat64Slice(t *testing.T) {
 
 	os.Setenv("BADFLOATS", "A,2.0,3.0")
 	cfg := &config{}
-	assert.EqualError(t, Parse(cfg), "env: parse error on field \"BadFloats\" of type \"[]float64\": strconv.ParseFloat: parsing \"A\": invalid syntax")
+	assert.EqualError(t, Parse(cfg), "env: parse error on field \"BadFloats\" of type \"[]float64\" (BADFLOATS=\"A,2.0,3.0\"): strconv.ParseFloat: parsing \"A\": invalid syntax")
 }
 
 func TestInvalidBoolsSlice(t *testing.T) {
@@ -564,7 +575,7 @@ func TestInvalidBoolsSlice(t *testing.T) {
 
 	os.Setenv("BADBOOLS", "t,f,TRUE,faaaalse")
 	cfg := &config{}
-	assert.EqualError(t, Parse(cfg), "env: parse error on field \"BadBools\" of type \"[]bool\": strconv.ParseBool: parsing \"faaaalse\": invalid syntax")
+	assert.EqualError(t, Parse(cfg), "env: parse error on field \"BadBools\" of type \"[]bool\" (BADBOOLS=\"t,f,TRUE,faaaalse\"): strconv.ParseBool: parsing \"faaaalse\": invalid syntax")
 }
 
 func TestInvalidDuration(t *testing.T) {
@@ -572,7 +583,7 @@ func TestInvalidDuration(t *testing.T) {
 	defer os.Clearenv()
 
 	cfg := Config{}
-	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Duration\" of type \"time.Duration\": unable to parse duration: time: invalid duration should-be-a-valid-duration")
+	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Duration\" of type \"time.Duration\" (DURATION=\"should-be-a-valid-duration\"): unable to parse duration: time: invalid duration should-be-a-valid-duration")
 }
 
 func TestInvalidDurations(t *testing.T) {
@@ -580,7 +591,7 @@ func TestInvalidDurations(t *testing.T) {
 	defer os.Clearenv()
 
 	cfg := Config{}
-	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Durations\" of type \"[]time.Duration\": unable to parse duration: time: invalid duration contains-an-invalid-duration")
+	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Durations\" of type \"[]time.Duration\" (DURATIONS=\"1s,contains-an-invalid-duration,3s\"): unable to parse duration: time: invalid duration contains-an-invalid-duration")
 }
 
 func TestParseStructWithoutEnvTag(t *testing.T) {
@@ -595,7 +606,7 @@ func TestParseStructWithInvalidFieldKind(t *testing.T) {
 	}
 	os.Setenv("BLAH", "a")
 	cfg := config{}
-	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"WontWorkByte\" of type \"uint8\": strconv.ParseUint: parsing \"a\": invalid syntax")
+	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"WontWorkByte\" of type \"uint8\" (BLAH=\"a\"): strconv.ParseUint: parsing \"a\": invalid syntax")
 }
 
 func TestUnsupportedSliceType(t *testing.T) {
@@ -619,7 +630,7 @@ func TestBadSeparator(t *testing.T) {
 	os.Setenv("WONTWORK", "1,2,3,4")
 	defer os.Clearenv()
 
-	assert.EqualError(t, Parse(cfg), "env: parse error on field \"WontWork\" of type \"[]int\": strconv.ParseInt: parsing \"1,2,3,4\": invalid syntax")
+	assert.EqualError(t, Parse(cfg), "env: parse error on field \"WontWork\" of type \"[]int\" (WONTWORK=\"1,2,3,4\"): strconv.ParseInt: parsing \"1,2,3,4\": invalid syntax")
 }
 
 func TestNoErrorRequiredSet(t *testing.T) {
@@ -766,7 +777,7 @@ func TestCustomParserError(t *testing.T) {
 		})
 
 		assert.Empty(t, cfg.Var.name)
-		assert.EqualError(t, err, "env: parse error on field \"Var\" of type \"env.foo\": something broke")
+		assert.EqualError(t, err, "env: parse error on field \"Var\" of type \"env.foo\" (VAR=\"single\"): something broke")
 	})
 
 	t.Run("slice", func(t *testing.T) {
@@ -781,7 +792,7 @@ func TestCustomParserError(t *testing.T) {
 		})
 
 		assert.Empty(t, cfg.Var)
-		assert.EqualError(t, err, "env: parse error on field \"Var\" of type \"[]env.foo\": something broke")
+		assert.EqualError(t, err, "env: parse error on field \"Var\" of type \"[]env.foo\" (VAR2=\"slice,slace\"): something broke")
 	})
 }
 
@@ -864,7 +875,7 @@ func TestTypeCustomParserBasicInvalid(t *testing.T) {
 	})
 
 	assert.Empty(t, cfg.Const)
-	assert.EqualError(t, err, "env: parse error on field \"Const\" of type \"env.ConstT\": random error")
+	assert.EqualError(t, err, "env: parse error on field \"Const\" of type \"env.ConstT\" (CONST_=\"foobar\"): random error")
 }
 
 func TestCustomParserNotCalledForNonAlias(t *testing.T) {
@@ -954,7 +965,7 @@ func TestTextUnmarshalerError(t *testing.T) {
 	}
 	os.Setenv("UNMARSHALER", "invalid")
 	cfg := &config{}
-	assert.EqualError(t, Parse(cfg), "env: parse error on field \"Unmarshaler\" of type \"env.unmarshaler\": time: invalid duration invalid")
+	assert.EqualError(t, Parse(cfg), "env: parse error on field \"Unmarshaler\" of type \"env.unmarshaler\" (UNMARSHALER=\"invalid\"): time: invalid duration invalid")
 }
 
 func TestTextUnmarshalersError(t *testing.T) {
@@ -963,7 +974,7 @@ func TestTextUnmarshalersError(t *testing.T) {
 	}
 	os.Setenv("UNMARSHALERS", "1s,invalid")
 	cfg := &config{}
-	assert.EqualError(t, Parse(cfg), "env: parse error on field \"Unmarshalers\" of type \"[]env.unmarshaler\": time: invalid duration invalid")
+	assert.EqualError(t, Parse(cfg), "env: parse error on field \"Unmarshalers\" of type \"[]env.unmarshaler\" (UNMARSHALERS=\"1s,invalid\"): time: invalid duration invalid")
 }
 
 func TestParseURL(t *testing.T) {
@@ -981,7 +992,7 @@ func TestParseInvalidURL(t *testing.T) {
 	}
 	var cfg config
 	os.Setenv("EXAMPLE_URL_2", "nope://s s/")
-	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"ExampleURL\" of type \"url.URL\": unable to parse URL: parse \"nope://s s/\": invalid character \" \" in host name")
+	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"ExampleURL\" of type \"url.URL\" (EXAMPLE_URL_2=\"nope://s s/\"): unable to parse URL: parse \"nope://s s/\": invalid character \" \" in host name")
 }
 
 func ExampleParse() {
@@ -1271,5 +1282,537 @@ func TestParsesEnvPrefixInnerFails(t *testing.T) {
 	}
 	os.Setenv("A_B_NUMBER", "not-a-number")
 	var cfg = config{}
-	assert.EqualError(t, ParsePrefix("A_", &cfg), "env: parse error on field \"Number\" of type \"int\": strconv.ParseInt: parsing \"not-a-number\": invalid syntax")
+	assert.EqualError(t, ParsePrefix("A_", &cfg), "env: parse error on field \"Number\" of type \"int\" (A_B_NUMBER=\"not-a-number\"): strconv.ParseInt: parsing \"not-a-number\": invalid syntax")
+}
+
+func TestStrict(t *testing.T) {
+	type config struct {
+		Port int    `env:"PORT"`
+		Host string `env:"HOST"`
+	}
+	defer os.Clearenv()
+	os.Setenv("APP_PORT", "8080")
+	os.Setenv("APP_HOST", "localhost")
+	os.Setenv("OTHER_THING", "ignored")
+
+	var cfg config
+	assert.NoError(t, ParsePrefix("APP_", &cfg, WithStrict()))
+	assert.Equal(t, 8080, cfg.Port)
+
+	os.Setenv("APP_PROT", "8080")
+	os.Setenv("APP_DEBUG", "true")
+	err := Parse(&cfg, WithPrefix("APP_"), WithStrict())
+	assert.EqualError(t, err, `env: unknown environment variables with prefix "APP_": APP_DEBUG, APP_PROT`)
+	var uerr UnusedVarsError
+	require.True(t, errors.As(err, &uerr))
+	assert.Equal(t, []string{"APP_DEBUG", "APP_PROT"}, uerr.Keys)
+}
+
+func TestStrictNested(t *testing.T) {
+	defer os.Clearenv()
+	os.Setenv("A_B_innervar", "someinnervalue")
+	os.Setenv("A_B_innernum", "8")
+	os.Setenv("A_C", "somevalue")
+	cfg := ParentPrefixStruct{
+		InnerStruct: &InnerStruct{},
+	}
+	assert.NoError(t, ParsePrefix("A_", &cfg, WithStrict()))
+}
+
+func TestStrictWithoutPrefix(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT"`
+	}
+	defer os.Clearenv()
+	os.Setenv("PROT", "8080")
+	var cfg config
+	assert.NoError(t, Parse(&cfg, WithStrict()))
+}
+
+func TestEscapedSeparator(t *testing.T) {
+	type config struct {
+		Strings []string `env:"STRINGS"`
+		Custom  []string `env:"CUSTOM" envSeparator:"::"`
+		Ints    []int    `env:"INTS"`
+	}
+	defer os.Clearenv()
+	os.Setenv("STRINGS", `a\,b,c,d\e,f\\,g\\\,h`)
+	os.Setenv("CUSTOM", `x\::y::z`)
+	os.Setenv("INTS", `1,2`)
+
+	var cfg config
+	assert.NoError(t, Parse(&cfg))
+	assert.Equal(t, []string{"a,b", "c", `d\e`, `f\`, `g\,h`}, cfg.Strings)
+	assert.Equal(t, []string{"x::y", "z"}, cfg.Custom)
+	assert.Equal(t, []int{1, 2}, cfg.Ints)
+}
+
+func TestUnexported(t *testing.T) {
+	type inner struct {
+		name string `env:"NAME"`
+	}
+	type config struct {
+		home  string        `env:"HOME"`
+		port  int           `env:"PORT" envDefault:"3000"`
+		hosts []string      `env:"HOSTS"`
+		dur   time.Duration `env:"DURATION"`
+		level LogLevel      `env:"LOG_LEVEL"`
+		inner inner         `envPrefix:"INNER_"`
+		ptr   *inner
+	}
+	defer os.Clearenv()
+	os.Setenv("HOME", "/tmp/fakehome")
+	os.Setenv("HOSTS", "a,b")
+	os.Setenv("DURATION", "1s")
+	os.Setenv("LOG_LEVEL", "debug")
+	os.Setenv("INNER_NAME", "foo")
+	os.Setenv("NAME", "bar")
+
+	cfg := config{ptr: &inner{}}
+	assert.NoError(t, Parse(&cfg, WithUnexported()))
+	assert.Equal(t, "/tmp/fakehome", cfg.home)
+	assert.Equal(t, 3000, cfg.port)
+	assert.Equal(t, []string{"a", "b"}, cfg.hosts)
+	assert.Equal(t, time.Second, cfg.dur)
+	assert.Equal(t, DebugLevel, cfg.level)
+	assert.Equal(t, "foo", cfg.inner.name)
+	assert.Equal(t, "bar", cfg.ptr.name)
+
+	var ignored config
+	assert.NoError(t, Parse(&ignored))
+	assert.Empty(t, ignored.home)
+}
+
+func TestNestedSlices(t *testing.T) {
+	type config struct {
+		Shards   [][]string         `env:"SHARDS" envSeparator:";" envInnerSeparator:","`
+		Matrix   [][]int            `env:"MATRIX"`
+		Levels   [][]LogLevel       `env:"LEVELS"`
+		Timeouts [][]*time.Duration `env:"TIMEOUTS"`
+	}
+	defer os.Clearenv()
+	os.Setenv("SHARDS", "a,b;c,d;e")
+	os.Setenv("MATRIX", "1|2,3|4")
+	os.Setenv("LEVELS", "debug|info,info")
+	os.Setenv("TIMEOUTS", "1s|2s")
+
+	var cfg config
+	assert.NoError(t, Parse(&cfg))
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, cfg.Shards)
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}}, cfg.Matrix)
+	assert.Equal(t, [][]LogLevel{{DebugLevel, InfoLevel}, {InfoLevel}}, cfg.Levels)
+	require.Len(t, cfg.Timeouts, 1)
+	require.Len(t, cfg.Timeouts[0], 2)
+	assert.Equal(t, 2*time.Second, *cfg.Timeouts[0][1])
+}
+
+func TestNestedSlicesInvalid(t *testing.T) {
+	type config struct {
+		Matrix [][]int `env:"MATRIX"`
+	}
+	defer os.Clearenv()
+	os.Setenv("MATRIX", "1|2,3|x")
+
+	var cfg config
+	assert.EqualError(t, Parse(&cfg), "env: parse error on field \"Matrix\" of type \"[][]int\" (MATRIX=\"1|2,3|x\"): strconv.ParseInt: parsing \"x\": invalid syntax")
+}
+
+func TestMaps(t *testing.T) {
+	type config struct {
+		Labels   map[string]string           `env:"LABELS"`
+		Ports    map[string]int              `env:"PORTS" envKeyValSeparator:"="`
+		Headers  map[string][]string         `env:"HEADERS" envKeyValSeparator:"="`
+		Levels   map[LogLevel]*time.Duration `env:"LEVELS"`
+		Selector map[string][]string         `env:"SELECTOR" envSeparator:";" envKeyValSeparator:"=" envInnerSeparator:","`
+	}
+	defer os.Clearenv()
+	os.Setenv("LABELS", "app:web,tier:front\\,end")
+	os.Setenv("PORTS", "http=80,https=443")
+	os.Setenv("HEADERS", "Accept=text/html|application/json,X-Id=1")
+	os.Setenv("LEVELS", "debug:1s")
+	os.Setenv("SELECTOR", "env=prod,staging;team=core")
+
+	var cfg config
+	assert.NoError(t, Parse(&cfg))
+	assert.Equal(t, map[string]string{"app": "web", "tier": "front,end"}, cfg.Labels)
+	assert.Equal(t, map[string]int{"http": 80, "https": 443}, cfg.Ports)
+	assert.Equal(t, map[string][]string{"Accept": {"text/html", "application/json"}, "X-Id": {"1"}}, cfg.Headers)
+	require.Contains(t, cfg.Levels, DebugLevel)
+	assert.Equal(t, time.Second, *cfg.Levels[DebugLevel])
+	assert.Equal(t, map[string][]string{"env": {"prod", "staging"}, "team": {"core"}}, cfg.Selector)
+}
+
+func TestMapsInvalid(t *testing.T) {
+	type config struct {
+		Ports map[string]int `env:"PORTS"`
+	}
+	defer os.Clearenv()
+	var cfg config
+
+	os.Setenv("PORTS", "http:80,https")
+	assert.EqualError(t, Parse(&cfg), `env: parse error on field "Ports" of type "map[string]int" (PORTS="http:80,https"): invalid map item: "https"`)
+
+	os.Setenv("PORTS", "http:eighty")
+	assert.EqualError(t, Parse(&cfg), `env: parse error on field "Ports" of type "map[string]int" (PORTS="http:eighty"): strconv.ParseInt: parsing "eighty": invalid syntax`)
+}
+
+func TestJSONArray(t *testing.T) {
+	type config struct {
+		Strings []string    `env:"STRINGS,jsonArray"`
+		Ints    []int       `env:"INTS,jsonArray"`
+		Levels  []*LogLevel `env:"LEVELS,jsonArray"`
+		Matrix  [][]string  `env:"MATRIX,jsonArray"`
+	}
+	defer os.Clearenv()
+	os.Setenv("STRINGS", `["a", "b c", "d,e"]`)
+	os.Setenv("INTS", `[1, "2", 3]`)
+	os.Setenv("LEVELS", `["debug"]`)
+	os.Setenv("MATRIX", `[["a,b"], [], ["c", "d"]]`)
+
+	var cfg config
+	assert.NoError(t, Parse(&cfg))
+	assert.Equal(t, []string{"a", "b c", "d,e"}, cfg.Strings)
+	assert.Equal(t, []int{1, 2, 3}, cfg.Ints)
+	require.Len(t, cfg.Levels, 1)
+	assert.Equal(t, DebugLevel, *cfg.Levels[0])
+	assert.Equal(t, [][]string{{"a,b"}, {}, {"c", "d"}}, cfg.Matrix)
+}
+
+func TestJSONArrayInvalid(t *testing.T) {
+	type config struct {
+		Strings []string `env:"STRINGS,jsonArray"`
+	}
+	defer os.Clearenv()
+	os.Setenv("STRINGS", `a,b`)
+
+	var cfg config
+	assert.EqualError(t, Parse(&cfg), `env: parse error on field "Strings" of type "[]string" (STRINGS="a,b"): invalid JSON array: invalid character 'a' looking for beginning of value`)
+}
+
+func TestShellWords(t *testing.T) {
+	type config struct {
+		Args   []string   `env:"ARGS,shellWords"`
+		Ints   []int      `env:"INTS,shellWords"`
+		Matrix [][]string `env:"MATRIX,shellWords"`
+		Empty  []string   `env:"EMPTY,shellWords"`
+	}
+	src := MapSource{
+		"ARGS":   `-v --name "John \"Jo\" Smith" 'a,b'  c\ d "" e"f"'g'`,
+		"INTS":   "1\t2\n 3",
+		"MATRIX": `"a 'b c'" d`,
+		"EMPTY":  "  ",
+	}
+
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(src)))
+	assert.Equal(t, []string{"-v", "--name", `John "Jo" Smith`, "a,b", "c d", "", "efg"}, cfg.Args)
+	assert.Equal(t, []int{1, 2, 3}, cfg.Ints)
+	assert.Equal(t, [][]string{{"a", "b c"}, {"d"}}, cfg.Matrix)
+	assert.Empty(t, cfg.Empty)
+
+	for value, msg := range map[string]string{
+		`a "b`: "unterminated double quote",
+		`a 'b`: "unterminated single quote",
+		`a\`:   "unterminated escape at the end of the value",
+	} {
+		err := Parse(&config{}, WithSource(MapSource{"ARGS": value}))
+		assert.EqualError(t, err, fmt.Sprintf(`env: parse error on field "Args" of type "[]string" (ARGS=%q): %s`, value, msg))
+	}
+}
+
+func TestWithSource(t *testing.T) {
+	type config struct {
+		Home string `env:"HOME"`
+		Port int    `env:"PORT" envDefault:"3000"`
+	}
+	defer os.Clearenv()
+	os.Setenv("HOME", "/tmp/fakehome")
+
+	vars := map[string]string{"PORT": "8080"}
+	var cfg config
+	assert.NoError(t, Parse(&cfg, WithSource(SourceFunc(func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}))))
+	assert.Equal(t, "", cfg.Home)
+	assert.Equal(t, 8080, cfg.Port)
+}
+
+func TestRequiredAsWarning(t *testing.T) {
+	type config struct {
+		Home string `env:"HOME,required"`
+		Port int    `env:"PORT,required" envDefault:"3000"`
+		Key  string `env:"KEY,required"`
+	}
+	defer os.Clearenv()
+	os.Setenv("KEY", "secret")
+
+	var cfg config
+	var warnings []string
+	err := Parse(&cfg, WithRequiredAsWarning(), WithWarningHook(func(err error) {
+		warnings = append(warnings, err.Error())
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`env: required environment variable "HOME" is not set`,
+		`env: required environment variable "PORT" is not set`,
+	}, warnings)
+	assert.Equal(t, "", cfg.Home)
+	assert.Equal(t, 3000, cfg.Port)
+	assert.Equal(t, "secret", cfg.Key)
+
+	assert.NoError(t, Parse(&cfg, WithRequiredAsWarning()))
+	assert.EqualError(t, Parse(&cfg), `env: required environment variable "HOME" is not set`)
+}
+
+func TestNoDefaults(t *testing.T) {
+	type config struct {
+		Home string `env:"HOME"`
+		Port int    `env:"PORT" envDefault:"3000"`
+		Host string `env:"HOST" envDefault:"localhost"`
+	}
+	var cfg config
+	assert.NoError(t, Parse(&cfg, WithSource(MapSource{"PORT": "80", "HOST": "example.com"}), WithNoDefaults()))
+	assert.Equal(t, config{Port: 80, Host: "example.com"}, cfg)
+
+	err := Parse(&cfg, WithSource(MapSource{"PORT": "80"}), WithNoDefaults())
+	assert.EqualError(t, err, `env: environment variable "HOST" is not set, and defaults are not allowed`)
+
+	var warnings []string
+	err = Parse(&cfg, WithSource(MapSource{}), WithPrefix("APP_"), WithNoDefaults(), WithRequiredAsWarning(), WithWarningHook(func(err error) {
+		warnings = append(warnings, err.Error())
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`env: environment variable "APP_PORT" is not set, and defaults are not allowed`,
+		`env: environment variable "APP_HOST" is not set, and defaults are not allowed`,
+	}, warnings)
+	assert.Equal(t, config{Port: 3000, Host: "localhost"}, cfg)
+}
+
+func TestChainSource(t *testing.T) {
+	type config struct {
+		Home string `env:"HOME"`
+		Port int    `env:"PORT"`
+		Host string `env:"HOST" envDefault:"localhost"`
+	}
+	first := SourceFunc(func(key string) (string, bool) {
+		if key == "PORT" {
+			return "8080", true
+		}
+		return "", false
+	})
+	second := SourceFunc(func(key string) (string, bool) {
+		return "second-" + key, key != "HOST"
+	})
+
+	var cfg config
+	assert.NoError(t, Parse(&cfg, WithSource(ChainSource(first, second))))
+	assert.Equal(t, config{Home: "second-HOME", Port: 8080, Host: "localhost"}, cfg)
+}
+
+func TestKeys(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+	os.Setenv("APP_PORT", "8080")
+	os.Setenv("APP_HOST", "localhost")
+	os.Setenv("OTHER", "x")
+
+	keys, err := Keys("APP_", OSSource{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"APP_HOST", "APP_PORT"}, keys)
+
+	remote := SourceFunc(func(key string) (string, bool) { return "", false })
+	chain := ChainSource(OSSource{}, remote, MapSource{"APP_DEBUG": "true", "APP_PORT": "80"})
+	keys, err = Keys("APP_", chain)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"APP_DEBUG", "APP_HOST", "APP_PORT"}, keys)
+
+	_, err = Keys("APP_", remote)
+	assert.Equal(t, ErrNotEnumerable, err)
+	_, err = Keys("APP_", ChainSource(remote))
+	assert.Equal(t, ErrNotEnumerable, err)
+}
+
+func TestStrictSource(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT"`
+	}
+	os.Clearenv()
+	defer os.Clearenv()
+	os.Setenv("APP_PROT", "1")
+
+	var cfg config
+	src := MapSource{"APP_PORT": "8080", "APP_DEBUG": "true"}
+	err := Parse(&cfg, WithPrefix("APP_"), WithStrict(), WithSource(src))
+	assert.EqualError(t, err, `env: unknown environment variables with prefix "APP_": APP_DEBUG`)
+
+	remote := SourceFunc(func(key string) (string, bool) { return "", false })
+	err = Parse(&cfg, WithPrefix("APP_"), WithStrict(), WithSource(remote))
+	assert.Equal(t, ErrNotEnumerable, err)
+}
+
+type failingSource struct{ err error }
+
+func (s failingSource) Lookup(key string) (string, bool) {
+	return "", false
+}
+
+func (s failingSource) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	if key == "HOME" {
+		return "", false, s.err
+	}
+	return "", false, nil
+}
+
+func TestContextSource(t *testing.T) {
+	type config struct {
+		Port int    `env:"PORT"`
+		Home string `env:"HOME"`
+		URL  string `env:"URL" envDefault:"http://${HOME}" envExpand:"true"`
+	}
+	os.Clearenv()
+	defer os.Clearenv()
+	os.Setenv("PORT", "8080")
+	src := failingSource{err: errors.New("connection refused")}
+
+	var cfg config
+	err := Parse(&cfg, WithSource(ChainSource(OSSource{}, src)))
+	assert.EqualError(t, err, `env: could not look up "HOME": connection refused`)
+	assert.True(t, errors.Is(err, src.err))
+
+	os.Setenv("HOME", "/home/user")
+	require.NoError(t, Parse(&cfg, WithSource(ChainSource(OSSource{}, src))))
+	assert.Equal(t, "http:///home/user", cfg.URL)
+}
+
+type ctxSource struct {
+	cancel context.CancelFunc
+	keys   []string
+}
+
+func (s *ctxSource) Lookup(key string) (string, bool) {
+	return "", false
+}
+
+func (s *ctxSource) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	s.keys = append(s.keys, key)
+	if key == "B" {
+		s.cancel()
+	}
+	return "value", true, ctx.Err()
+}
+
+func TestParseWithContext(t *testing.T) {
+	type config struct {
+		A string `env:"A"`
+		B string `env:"B"`
+		C string `env:"C"`
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	src := &ctxSource{cancel: cancel}
+
+	var cfg config
+	err := ParseWithContext(ctx, &cfg, WithSource(src))
+	assert.EqualError(t, err, `env: could not look up "B": context canceled`)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, []string{"A", "B"}, src.keys)
+
+	err = ParseWithContext(ctx, &cfg)
+	assert.EqualError(t, err, "env: context canceled")
+
+	require.NoError(t, ParseWithContext(context.Background(), &cfg, WithSource(MapSource{"A": "a"})))
+	assert.Equal(t, "a", cfg.A)
+}
+
+func TestParseAll(t *testing.T) {
+	type server struct {
+		Port int `env:"PORT"`
+	}
+	type database struct {
+		URL string `env:"DATABASE_URL"`
+	}
+	defer os.Clearenv()
+	os.Setenv("APP_PORT", "8080")
+	os.Setenv("APP_DATABASE_URL", "postgres://")
+
+	var srv server
+	var db database
+	require.NoError(t, ParseAll([]interface{}{&srv, &db}, WithPrefix("APP_"), WithStrict()))
+	assert.Equal(t, 8080, srv.Port)
+	assert.Equal(t, "postgres://", db.URL)
+
+	os.Setenv("APP_PROT", "8080")
+	err := ParseAll([]interface{}{&srv, &db}, WithPrefix("APP_"), WithStrict())
+	assert.EqualError(t, err, `env: unknown environment variables with prefix "APP_": APP_PROT`)
+
+	assert.Equal(t, ErrNotAStructPtr, ParseAll([]interface{}{&srv, db}))
+}
+
+func TestNoPrefix(t *testing.T) {
+	type config struct {
+		Port  int    `env:"PORT"`
+		Home  string `env:"HOME,noprefix"`
+		User  string `env:"USER"`
+		Inner struct {
+			Shell string `env:"SHELL,noprefix"`
+		} `envPrefix:"INNER_"`
+		URL string `env:"URL,noprefix" envDefault:"http://${HOME}:${APP_PORT}" envExpand:"true"`
+	}
+	defer os.Clearenv()
+	os.Setenv("APP_PORT", "8080")
+	os.Setenv("APP_HOME", "wrong")
+	os.Setenv("HOME", "/home/user")
+	os.Setenv("USER", "user")
+	os.Setenv("APP_USER", "wrong")
+	os.Setenv("INNER_SHELL", "/bin/sh")
+
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithPrefix("APP_"), WithPrefixExceptions("USER")))
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, "/home/user", cfg.Home)
+	assert.Equal(t, "user", cfg.User)
+	assert.Equal(t, "/bin/sh", cfg.Inner.Shell)
+	assert.Equal(t, "http:///home/user:8080", cfg.URL)
+}
+
+func TestMaxValueLength(t *testing.T) {
+	type config struct {
+		Name  string   `env:"NAME"`
+		Hosts []string `env:"HOSTS"`
+		Cert  string   `env:"CERT,file"`
+	}
+	defer os.Clearenv()
+	os.Setenv("APP_NAME", "short")
+	os.Setenv("APP_HOSTS", strings.Repeat("a,", 8))
+
+	var cfg config
+	err := Parse(&cfg, WithPrefix("APP_"), WithMaxValueLength(10))
+	assert.EqualError(t, err, `env: value of environment variable "APP_HOSTS" is too long: 16 bytes, the limit is 10`)
+
+	file := filepath.Join(t.TempDir(), "cert")
+	require.NoError(t, os.WriteFile(file, []byte(strings.Repeat("x", 11)), 0600))
+	os.Setenv("APP_HOSTS", "a,b")
+	os.Setenv("APP_CERT", file)
+	err = Parse(&cfg, WithPrefix("APP_"), WithMaxValueLength(10))
+	assert.EqualError(t, err, `env: value of environment variable "APP_CERT" is too long: 11 bytes, the limit is 10`)
+
+	require.NoError(t, Parse(&cfg, WithPrefix("APP_")))
+}
+
+func TestParseAs(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+	cfg, err := ParseAs[config](WithSource(MapSource{"HOST": "localhost", "PORT": "8080"}))
+	require.NoError(t, err)
+	assert.Equal(t, config{Host: "localhost", Port: 8080}, cfg)
+
+	cfg, err = ParseAs[config](WithSource(MapSource{"HOST": "localhost", "PORT": "http"}))
+	assert.Error(t, err)
+	assert.Equal(t, config{}, cfg)
+
+	_, err = ParseAs[int]()
+	assert.EqualError(t, err, "env: expected a pointer to a Struct")
 }
@@ -0,0 +1,64 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// environ is a snapshot of the names of the process environment variables,
+// sorted so that the names sharing a prefix are found without scanning the
+// whole environment.
+type environ []string
+
+func snapshotEnviron() environ {
+	vars := os.Environ()
+	keys := make(environ, 0, len(vars))
+	for _, kv := range vars {
+		key, _, _ := strings.Cut(kv, "=")
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// withPrefix returns the sorted names starting with prefix.
+func (e environ) withPrefix(prefix string) []string {
+	i := sort.SearchStrings(e, prefix)
+	j := i
+	for j < len(e) && strings.HasPrefix(e[j], prefix) {
+		j++
+	}
+	return e[i:j]
+}
+
+// withEnviron makes the parser use e instead of taking its own snapshot.
+func withEnviron(e environ) Option {
+	return func(o *Options) {
+		o.environ = e
+	}
+}
+
+// WithEnviron sets vars in the process environment, calls fn, then restores
+// the environment as it was before, even if fn panics, and returns the
+// error of fn. It suits integration tests and tools evaluating
+// configurations in turn, but as it changes the environment of the whole
+// process, calls must not overlap with each other or with code reading the
+// environment.
+func WithEnviron(vars map[string]string, fn func() error) error {
+	prev := os.Environ()
+	defer func() {
+		os.Clearenv()
+		for _, kv := range prev {
+			key, value, _ := strings.Cut(kv, "=")
+			os.Setenv(key, value)
+		}
+	}()
+	for key, value := range vars {
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("env: could not set %q: %w", key, err)
+		}
+	}
+	return fn()
+}
@@ -0,0 +1,49 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEnviron(t *testing.T) {
+	defer os.Clearenv()
+	os.Setenv("KEPT", "before")
+
+	type config struct {
+		Kept string `env:"KEPT"`
+		Port int    `env:"PORT"`
+	}
+	var cfg config
+	err := WithEnviron(map[string]string{"KEPT": "during", "PORT": "8080"}, func() error {
+		return Parse(&cfg)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, config{Kept: "during", Port: 8080}, cfg)
+	assert.Equal(t, "before", os.Getenv("KEPT"))
+	_, ok := os.LookupEnv("PORT")
+	assert.False(t, ok)
+
+	errBoom := errors.New("boom")
+	assert.Equal(t, errBoom, WithEnviron(nil, func() error { return errBoom }))
+}
+
+func TestWithEnvironPanic(t *testing.T) {
+	defer os.Clearenv()
+	os.Setenv("KEPT", "before")
+
+	assert.Panics(t, func() {
+		_ = WithEnviron(map[string]string{"KEPT": "during", "PORT": "8080"}, func() error {
+			os.Setenv("OTHER", "x")
+			panic("boom")
+		})
+	})
+	assert.Equal(t, "before", os.Getenv("KEPT"))
+	for _, key := range []string{"PORT", "OTHER"} {
+		_, ok := os.LookupEnv(key)
+		assert.False(t, ok, key)
+	}
+}
@@ -0,0 +1,16 @@
+// Command envlint reports invalid `env` struct tags, as described in package
+// envlint.
+//
+// Usage:
+//
+//	envlint [-unexported] ./...
+package main
+
+import (
+	"github.com/conradludgate/env/v6/envlint"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(envlint.Analyzer)
+}
@@ -0,0 +1,261 @@
+// Package envlint provides an analysis.Analyzer reporting mistakes in the
+// `env` struct tags read by github.com/conradludgate/env, so that they fail
+// the build rather than Parse at startup:
+//
+//   - unknown tag options, e.g. `env:"PORT,requried"`, envReload values and
+//     envEncoding encodings, and encodings not ending with base64
+//   - keys used by several fields of the same struct, nested structs included
+//   - separators on fields that are not slices or maps
+//   - the reread option on fields that are not env.Lazy, and envGroup tags
+//     on fields that are
+//   - `env` tags on unexported fields, which Parse ignores unless given
+//     env.WithUnexported; pass -unexported to allow them
+//
+// It is its own module, to keep golang.org/x/tools out of the dependencies
+// of applications using env. It can be run on its own with cmd/envlint, or
+// added to a multichecker.
+package envlint
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Analyzer reports invalid `env` struct tags.
+var Analyzer = &analysis.Analyzer{
+	Name:     "envlint",
+	Doc:      "report invalid env struct tags",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// nolint: gochecknoglobals
+var (
+	unexported bool
+
+	// options lists the options of the `env` tag supported by Parse.
+	options = map[string]bool{
+		"":           true,
+		"file":       true,
+		"required":   true,
+		"sensitive":  true,
+		"noprefix":   true,
+		"relative":   true,
+		"jsonArray":  true,
+		"shellWords": true,
+		"reread":     true,
+		"trim":       true,
+		"unquote":    true,
+		"multiline":  true,
+		"base64":     true,
+		"chunked":    true,
+	}
+
+	// encodings lists the encodings of the `envEncoding` tag supported by
+	// Parse.
+	encodings = map[string]bool{
+		"base64": true,
+		"gzip":   true,
+	}
+)
+
+func init() {
+	Analyzer.Flags.BoolVar(&unexported, "unexported", false, "allow env tags on unexported fields, as with env.WithUnexported")
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	ins.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		st := n.(*ast.StructType)
+		for _, field := range st.Fields.List {
+			checkField(pass, field)
+		}
+		if s, ok := pass.TypesInfo.TypeOf(st).(*types.Struct); ok {
+			checkDuplicates(pass, s)
+		}
+	})
+	return nil, nil
+}
+
+func checkField(pass *analysis.Pass, field *ast.Field) {
+	tag := fieldTag(field.Tag)
+	key, ok := tag.Lookup("env")
+	if !ok && !hasSeparator(tag) {
+		return
+	}
+	opts := strings.Split(key, ",")
+	for _, opt := range opts[1:] {
+		if !options[opt] {
+			pass.Reportf(field.Tag.Pos(), "env: tag option %q not supported", opt)
+		}
+	}
+	if reload, ok := tag.Lookup("envReload"); ok && reload != "hot" && reload != "restart" {
+		pass.Reportf(field.Tag.Pos(), "env: envReload %q not supported, expected hot or restart", reload)
+	}
+	if encoding, ok := tag.Lookup("envEncoding"); ok {
+		encs := strings.Split(encoding, ",")
+		for _, enc := range encs {
+			if !encodings[enc] {
+				pass.Reportf(field.Tag.Pos(), "env: envEncoding %q not supported", enc)
+			}
+		}
+		if encs[len(encs)-1] != "base64" && !containsString(opts[1:], "base64") {
+			pass.Reportf(field.Tag.Pos(), "env: envEncoding %q must end with base64, as variables cannot hold binary values", encoding)
+		}
+	}
+	if ok && opts[0] != "" && !unexported {
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				pass.Reportf(name.Pos(), "env: field %s is unexported and is not parsed", name.Name)
+			}
+		}
+	}
+	fieldType := pass.TypesInfo.TypeOf(field.Type)
+	if containsString(opts[1:], "reread") && !isEnvType(fieldType, "Lazy") {
+		pass.Reportf(field.Tag.Pos(), "env: reread option on field of type %s, which is not env.Lazy", fieldType)
+	}
+	if _, ok := tag.Lookup("envGroup"); ok && isEnvType(fieldType, "Lazy") {
+		pass.Reportf(field.Tag.Pos(), "env: envGroup on env.Lazy field, which is not supported")
+	}
+	typ := elemType(fieldType)
+	if typ == nil {
+		return
+	}
+	slice, _ := typ.Underlying().(*types.Slice)
+	m, _ := typ.Underlying().(*types.Map)
+	if _, ok := tag.Lookup("envSeparator"); ok && slice == nil && m == nil {
+		pass.Reportf(field.Tag.Pos(), "env: envSeparator on field of type %s, which is not a slice or a map", typ)
+	}
+	if _, ok := tag.Lookup("envKeyValSeparator"); ok && m == nil {
+		pass.Reportf(field.Tag.Pos(), "env: envKeyValSeparator on field of type %s, which is not a map", typ)
+	}
+	if _, ok := tag.Lookup("envInnerSeparator"); ok {
+		var elem types.Type
+		if slice != nil {
+			elem = slice.Elem()
+		} else if m != nil {
+			elem = m.Elem()
+		}
+		if elem == nil {
+			pass.Reportf(field.Tag.Pos(), "env: envInnerSeparator on field of type %s, which is not a slice or a map", typ)
+		} else if _, ok := elem.Underlying().(*types.Slice); !ok {
+			pass.Reportf(field.Tag.Pos(), "env: envInnerSeparator on field of type %s, whose elements are not slices", typ)
+		}
+	}
+}
+
+// checkDuplicates reports keys used by several fields of s, including the
+// fields of nested structs. Keys used twice within a single nested struct
+// are left to the check of that struct.
+func checkDuplicates(pass *analysis.Pass, s *types.Struct) {
+	type occurrence struct {
+		via  *types.Var
+		path string
+	}
+	seen := map[string]occurrence{}
+	for i := 0; i < s.NumFields(); i++ {
+		f := s.Field(i)
+		walkKeys(f, reflect.StructTag(s.Tag(i)), "", f.Name(), map[types.Type]bool{}, func(key, path string) {
+			prev, ok := seen[key]
+			if !ok {
+				seen[key] = occurrence{via: f, path: path}
+				return
+			}
+			if prev.via != f {
+				pass.Reportf(f.Pos(), "env: key %q of field %s is also used by field %s", key, path, prev.path)
+			}
+		})
+	}
+}
+
+// walkKeys calls fn with the key and path of f, tagged with tag, if it has
+// one, or of the fields of the struct it holds otherwise, as Parse reads
+// them.
+func walkKeys(f *types.Var, tag reflect.StructTag, prefix, path string, visiting map[types.Type]bool, fn func(key, path string)) {
+	if !f.Exported() && !unexported {
+		return
+	}
+	key := strings.Split(tag.Get("env"), ",")[0]
+	if key != "" {
+		fn(prefix+key, path)
+		return
+	}
+	typ := f.Type()
+	if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	s, ok := typ.Underlying().(*types.Struct)
+	if !ok || visiting[typ] {
+		return
+	}
+	visiting[typ] = true
+	defer delete(visiting, typ)
+	prefix += tag.Get("envPrefix")
+	for i := 0; i < s.NumFields(); i++ {
+		nested := s.Field(i)
+		walkKeys(nested, reflect.StructTag(s.Tag(i)), prefix, path+"."+nested.Name(), visiting, fn)
+	}
+}
+
+func fieldTag(lit *ast.BasicLit) reflect.StructTag {
+	if lit == nil || lit.Kind != token.STRING {
+		return ""
+	}
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return ""
+	}
+	return reflect.StructTag(s)
+}
+
+func hasSeparator(tag reflect.StructTag) bool {
+	for _, name := range []string{"envSeparator", "envKeyValSeparator", "envInnerSeparator"} {
+		if _, ok := tag.Lookup(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// elemType returns the type parsed for a field of type t: the pointed to
+// type of pointers, and T for env.Tracked[T] and env.Lazy[T].
+func elemType(t types.Type) types.Type {
+	if t == nil {
+		return nil
+	}
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if isEnvType(t, "Tracked") || isEnvType(t, "Lazy") {
+		return t.(*types.Named).TypeArgs().At(0)
+	}
+	return t
+}
+
+// isEnvType reports whether t is an instance of the generic type name of the
+// env package.
+func isEnvType(t types.Type, name string) bool {
+	named, ok := t.(*types.Named)
+	if !ok || named.TypeArgs().Len() != 1 {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "github.com/conradludgate/env/v6" && obj.Name() == name
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package a
+
+import (
+	"time"
+
+	"github.com/conradludgate/env/v6"
+)
+
+type config struct {
+	Port    int               `env:"PORT,requried"` // want `env: tag option "requried" not supported`
+	Home    string            `env:"HOME,required,noprefix"`
+	Timeout time.Duration     `env:"TIMEOUT" envSeparator:":"` // want `env: envSeparator on field of type time.Duration, which is not a slice or a map`
+	Hosts   []string          `env:"HOSTS" envSeparator:":"`
+	Headers map[string]string `env:"HEADERS" envKeyValSeparator:"="`
+	Tags    []string          `env:"TAGS" envKeyValSeparator:"="` // want `env: envKeyValSeparator on field of type \[\]string, which is not a map`
+	Groups  [][]string        `env:"GROUPS" envInnerSeparator:";"`
+	Names   []string          `env:"NAMES" envInnerSeparator:";"` // want `env: envInnerSeparator on field of type \[\]string, whose elements are not slices`
+	secret  string            `env:"SECRET"`                      // want `env: field secret is unexported and is not parsed`
+	Reload  string            `env:"RELOAD" envReload:"live"`     // want `env: envReload "live" not supported, expected hot or restart`
+	Bundle  string            `env:"BUNDLE" envEncoding:"gzip,base64"`
+	Blob    string            `env:"BLOB" envEncoding:"zstd,base64"` // want `env: envEncoding "zstd" not supported`
+	Gzip    string            `env:"GZIP" envEncoding:"gzip"`        // want `env: envEncoding "gzip" must end with base64, as variables cannot hold binary values`
+	Gzip64  string            `env:"GZIP64,base64" envEncoding:"gzip"`
+	Other   string            `env:"PORT"` // want `env: key "PORT" of field Other is also used by field Port`
+}
+
+type database struct {
+	URL  string `env:"URL"`
+	Name string `env:"NAME"`
+}
+
+type services struct {
+	Primary   database  `envPrefix:"PRIMARY_"`
+	Secondary *database `envPrefix:"SECONDARY_"`
+	Replica   database  `envPrefix:"PRIMARY_"` // want `env: key "PRIMARY_URL" of field Replica.URL is also used by field Primary.URL` `env: key "PRIMARY_NAME" of field Replica.Name is also used by field Primary.Name`
+}
+
+type lazy struct {
+	Token   env.Lazy[string]        `env:"TOKEN,reread"`
+	Name    string                  `env:"NAME,reread"`              // want `env: reread option on field of type string, which is not env.Lazy`
+	Timeout env.Lazy[time.Duration] `env:"TIMEOUT" envSeparator:","` // want `env: envSeparator on field of type time.Duration, which is not a slice or a map`
+	User    env.Lazy[string]        `env:"USER" envGroup:"auth"`     // want `env: envGroup on env.Lazy field, which is not supported`
+}
@@ -0,0 +1,7 @@
+// Package env stubs the generic types of github.com/conradludgate/env
+// checked by envlint.
+package env
+
+type Tracked[T any] struct{ value T }
+
+type Lazy[T any] struct{ value *T }
@@ -0,0 +1,52 @@
+// Package envtest helps tests exercising env.Parse change the process
+// environment and put it back as it was.
+//
+// The process environment is shared by every goroutine, so these helpers
+// must not be used by tests running in parallel.
+package envtest
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/conradludgate/env/v6"
+)
+
+// SetForTest sets the variable named by key to value for the duration of
+// the test, restoring its previous value, or unsetting it, on cleanup. It
+// wraps t.Setenv, and likewise panics in parallel tests.
+func SetForTest(t testing.TB, key, value string) {
+	t.Helper()
+	t.Setenv(key, value)
+}
+
+// Environment is a copy of the process environment, as taken by Snapshot.
+type Environment map[string]string
+
+// Snapshot returns a copy of the process environment.
+func Snapshot() Environment {
+	e := Environment{}
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		e[key] = value
+	}
+	return e
+}
+
+// Restore makes the process environment hold exactly the variables of e.
+func (e Environment) Restore() {
+	os.Clearenv()
+	for key, value := range e {
+		os.Setenv(key, value)
+	}
+}
+
+// WithEnv sets vars, calls fn, then restores the process environment as it
+// was before, even if fn panics.
+func WithEnv(vars map[string]string, fn func()) {
+	_ = env.WithEnviron(vars, func() error {
+		fn()
+		return nil
+	})
+}
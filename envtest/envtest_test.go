@@ -0,0 +1,70 @@
+package envtest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/conradludgate/env/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetForTest(t *testing.T) {
+	os.Setenv("ENVTEST_KEPT", "before")
+	defer os.Unsetenv("ENVTEST_KEPT")
+
+	t.Run("set", func(t *testing.T) {
+		SetForTest(t, "ENVTEST_KEPT", "during")
+		SetForTest(t, "ENVTEST_NEW", "new")
+
+		var cfg struct {
+			Kept string `env:"ENVTEST_KEPT"`
+			New  string `env:"ENVTEST_NEW"`
+		}
+		require.NoError(t, env.Parse(&cfg))
+		assert.Equal(t, "during", cfg.Kept)
+		assert.Equal(t, "new", cfg.New)
+	})
+
+	assert.Equal(t, "before", os.Getenv("ENVTEST_KEPT"))
+	_, ok := os.LookupEnv("ENVTEST_NEW")
+	assert.False(t, ok)
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	os.Setenv("ENVTEST_KEPT", "before")
+	defer os.Unsetenv("ENVTEST_KEPT")
+
+	snapshot := Snapshot()
+	os.Setenv("ENVTEST_KEPT", "after")
+	os.Setenv("ENVTEST_NEW", "new")
+	snapshot.Restore()
+
+	assert.Equal(t, "before", os.Getenv("ENVTEST_KEPT"))
+	_, ok := os.LookupEnv("ENVTEST_NEW")
+	assert.False(t, ok)
+}
+
+func TestWithEnv(t *testing.T) {
+	var port int
+	WithEnv(map[string]string{"ENVTEST_PORT": "8080"}, func() {
+		var cfg struct {
+			Port int `env:"ENVTEST_PORT"`
+		}
+		require.NoError(t, env.Parse(&cfg))
+		port = cfg.Port
+	})
+	assert.Equal(t, 8080, port)
+	_, ok := os.LookupEnv("ENVTEST_PORT")
+	assert.False(t, ok)
+}
+
+func TestWithEnvPanic(t *testing.T) {
+	assert.Panics(t, func() {
+		WithEnv(map[string]string{"ENVTEST_PORT": "8080"}, func() {
+			panic("boom")
+		})
+	})
+	_, ok := os.LookupEnv("ENVTEST_PORT")
+	assert.False(t, ok)
+}
@@ -0,0 +1,106 @@
+package envtest
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FakeSource is an in-memory env.Source recording the keys looked up in it,
+// so that tests can assert which variables a configuration reads:
+//
+//	src := envtest.NewFakeSource(map[string]string{"PORT": "8080"})
+//	err := env.Parse(&cfg, env.WithSource(src))
+//	assert.Equal(t, []string{"HOST", "PORT"}, src.Lookups())
+//
+// Like env.MapSource, it can list and unset its variables, for strict mode
+// and env.WithUnset. It is safe for concurrent use.
+type FakeSource struct {
+	mu      sync.Mutex
+	vars    map[string]string
+	lookups []string
+}
+
+// NewFakeSource returns a FakeSource holding a copy of vars.
+func NewFakeSource(vars map[string]string) *FakeSource {
+	s := &FakeSource{vars: map[string]string{}}
+	for k, v := range vars {
+		s.vars[k] = v
+	}
+	return s
+}
+
+// Lookup retrieves the value of the variable named by key, and records the
+// lookup.
+func (s *FakeSource) Lookup(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lookups = append(s.lookups, key)
+	v, ok := s.vars[key]
+	return v, ok
+}
+
+// Keys returns the names of the variables starting with prefix.
+func (s *FakeSource) Keys(prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key := range s.vars {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// Set sets the variable named by key.
+func (s *FakeSource) Set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vars[key] = value
+}
+
+// Unset removes the variable named by key.
+func (s *FakeSource) Unset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.vars, key)
+	return nil
+}
+
+// Lookups returns the sorted keys looked up so far, each listed once,
+// whether or not they were set.
+func (s *FakeSource) Lookups() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, key := range s.lookups {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LookupCount returns how many times key was looked up.
+func (s *FakeSource) LookupCount(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, k := range s.lookups {
+		if k == key {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset forgets the lookups recorded so far.
+func (s *FakeSource) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lookups = nil
+}
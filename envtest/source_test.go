@@ -0,0 +1,44 @@
+package envtest
+
+import (
+	"testing"
+
+	"github.com/conradludgate/env/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeSource(t *testing.T) {
+	src := NewFakeSource(map[string]string{"APP_PORT": "8080", "APP_HOST": "localhost"})
+
+	var cfg struct {
+		Host  string `env:"HOST"`
+		Port  int    `env:"PORT"`
+		Debug bool   `env:"DEBUG"`
+	}
+	require.NoError(t, env.Parse(&cfg, env.WithPrefix("APP_"), env.WithSource(src)))
+	assert.Equal(t, "localhost", cfg.Host)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, []string{"APP_DEBUG", "APP_HOST", "APP_PORT"}, src.Lookups())
+	assert.Equal(t, 1, src.LookupCount("APP_PORT"))
+
+	src.Reset()
+	assert.Empty(t, src.Lookups())
+}
+
+func TestFakeSourceStrictAndUnset(t *testing.T) {
+	src := NewFakeSource(map[string]string{"APP_PORT": "8080"})
+	src.Set("APP_PROT", "8080")
+
+	var cfg struct {
+		Port int `env:"PORT"`
+	}
+	err := env.Parse(&cfg, env.WithPrefix("APP_"), env.WithSource(src), env.WithStrict())
+	assert.EqualError(t, err, `env: unknown environment variables with prefix "APP_": APP_PROT`)
+
+	require.NoError(t, src.Unset("APP_PROT"))
+	require.NoError(t, env.Parse(&cfg, env.WithPrefix("APP_"), env.WithSource(src), env.WithStrict(), env.WithUnset()))
+	keys, err := src.Keys("APP_")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
@@ -0,0 +1,20 @@
+package env
+
+import "reflect"
+
+// Equal reports whether a and b, structs or pointers to structs of the same
+// type, hold the same configuration, i.e. whether the fields Parse populates
+// are deeply equal, as Diff finds them. Fields without an `env` tag, outside
+// of nested structs, are ignored, so that reloading code can skip work when
+// nothing effective changed, whatever other state the structs carry.
+func Equal(a, b interface{}) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() {
+		return !va.IsValid() && !vb.IsValid()
+	}
+	if va.Type() != vb.Type() {
+		return false
+	}
+	changes, err := Diff(a, b)
+	return err == nil && len(changes) == 0
+}
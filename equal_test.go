@@ -0,0 +1,57 @@
+package env
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEqual(t *testing.T) {
+	type database struct {
+		URL string `env:"URL"`
+	}
+	type config struct {
+		Hosts    []string      `env:"HOSTS"`
+		Timeout  time.Duration `env:"TIMEOUT"`
+		Database *database     `envPrefix:"DB_"`
+		Inner    struct {
+			Name string `env:"NAME"`
+		}
+		LoadedAt time.Time
+	}
+	a := config{Hosts: []string{"a"}, Timeout: time.Second, Database: &database{URL: "db"}, LoadedAt: time.Now()}
+	a.Inner.Name = "x"
+	b := config{Hosts: []string{"a"}, Timeout: time.Second, Database: &database{URL: "db"}}
+	b.Inner.Name = "x"
+
+	assert.True(t, Equal(a, b))
+	assert.True(t, Equal(&a, &b))
+	assert.False(t, Equal(a, &b))
+
+	b.Hosts = []string{"b"}
+	assert.False(t, Equal(a, b))
+	b.Hosts = []string{"a"}
+
+	b.Database.URL = "other"
+	assert.False(t, Equal(a, b))
+	b.Database = nil
+	assert.False(t, Equal(a, b))
+	a.Database = nil
+	assert.True(t, Equal(a, b))
+
+	b.Inner.Name = "y"
+	assert.False(t, Equal(a, b))
+
+	b.Database = &database{}
+	assert.False(t, Equal(a, b))
+	b.Database = nil
+
+	var none *config
+	assert.False(t, Equal(none, &config{}))
+	assert.True(t, Equal(none, none))
+	assert.False(t, Equal(1, 1))
+
+	assert.True(t, Equal(nil, nil))
+	assert.False(t, Equal(a, nil))
+}
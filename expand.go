@@ -0,0 +1,186 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ExpansionDialect selects the syntax of the references to variables in the
+// values of fields with the `envExpand` tag.
+type ExpansionDialect int
+
+const (
+	// ShellDialect recognizes `$VAR` and `${VAR}` references, the default.
+	ShellDialect ExpansionDialect = iota
+	// WindowsDialect also recognizes the `%VAR%` references of Windows
+	// batch files, where `%%` stands for a literal percent sign.
+	WindowsDialect
+)
+
+// WithExpansionDialect sets the syntax of the references to variables in the
+// values of fields with the `envExpand` tag, e.g. WindowsDialect for
+// configurations shared with Windows services and batch tooling.
+func WithExpansionDialect(dialect ExpansionDialect) Option {
+	return func(o *Options) {
+		o.ExpansionDialect = dialect
+	}
+}
+
+// expansionOperators are the operators of the POSIX shell parameter
+// expansion supported in `${VAR<op>word}` references. With a colon, a
+// variable set to an empty string is treated as unset.
+var expansionOperators = []string{":-", ":?", ":+", "-", "?", "+"}
+
+// expandValue replaces the references to variables in val, the value of the
+// field described by params, appending the names of the variables to refs.
+//
+// Besides `$VAR` and `${VAR}`, references may use the operators of the
+// shell: `${VAR:-default}` uses default if VAR is not set,
+// `${VAR:?message}` fails with message if VAR is not set, and
+// `${VAR:+alt}` uses alt if VAR is set, and nothing otherwise. The default
+// and alternative values may themselves contain `$VAR` references.
+//
+// With ExpansionDepth, the references in the values of the variables
+// referred to are replaced too, except in the values of fields with the
+// `envExpand` tag, which are expanded already.
+func (p *parser) expandValue(params FieldParams, val string, refs *[]string) (string, error) {
+	var mapping func(ref string) string
+	expandString := func(s string) string {
+		if p.ExpansionDialect == WindowsDialect {
+			return expandWindows(s, mapping)
+		}
+		return os.Expand(s, mapping)
+	}
+	hasReferences := func(s string) bool {
+		return strings.Contains(s, "$") || p.ExpansionDialect == WindowsDialect && strings.Contains(s, "%")
+	}
+	// stack holds the variables whose values are being expanded.
+	var stack []string
+	lookup := func(name string) (string, bool) {
+		value, ok := p.expand(name)
+		if !ok || p.ExpansionDepth == 0 || !hasReferences(value) || p.isDeferred(name) || p.expandErr != nil {
+			return value, ok
+		}
+		for i, n := range stack {
+			if n == name {
+				cycle := append(append([]string{}, stack[i:]...), name)
+				p.expandErr = fmt.Errorf("env: cycle detected in expansion: %s", strings.Join(cycle, " -> "))
+				return "", ok
+			}
+		}
+		if len(stack) == p.ExpansionDepth {
+			p.expandErr = fmt.Errorf("env: expansion of %q exceeds the maximum depth of %d: %s", params.Key, p.ExpansionDepth, strings.Join(append(stack, name), " -> "))
+			return "", ok
+		}
+		stack = append(stack, name)
+		value = expandString(value)
+		stack = stack[:len(stack)-1]
+		return value, ok
+	}
+	mapping = func(ref string) string {
+		name, op, word := splitExpansion(ref)
+		*refs = append(*refs, name)
+		value, ok := lookup(name)
+		set := ok && (value != "" || !strings.HasPrefix(op, ":"))
+		switch strings.TrimPrefix(op, ":") {
+		case "-":
+			if set {
+				return value
+			}
+			return expandString(word)
+		case "+":
+			if set {
+				return expandString(word)
+			}
+			return ""
+		case "?":
+			if !set && p.expandErr == nil {
+				p.expandErr = fmt.Errorf(`env: %s: %s`, name, word)
+				if word == "" {
+					p.expandErr = fmt.Errorf(`env: environment variable %q referred to by %q is not set`, name, params.Key)
+				}
+			}
+			return value
+		}
+		if !ok && p.StrictExpansion && p.expandErr == nil {
+			p.expandErr = fmt.Errorf(`env: environment variable %q referred to by %q is not set`, name, params.Key)
+		}
+		return value
+	}
+	val = expandString(val)
+	if p.expandErr != nil {
+		return "", p.expandErr
+	}
+	return val, nil
+}
+
+// isDeferred reports whether key is the key of a field with the `envExpand`
+// tag.
+func (p *parser) isDeferred(key string) bool {
+	for _, d := range p.deferred {
+		if d.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// WithRecursiveExpansion makes the references in the values of the
+// variables referred to by fields with the `envExpand` tag be replaced too,
+// up to depth levels, e.g. for chained template variables. Cyclic references
+// and chains longer than depth are reported as errors.
+func WithRecursiveExpansion(depth int) Option {
+	return func(o *Options) {
+		o.ExpansionDepth = depth
+	}
+}
+
+// expandWindows is like os.Expand, except that it also replaces `%VAR%`
+// references, and `%%` with `%`. Percent signs that are not part of such a
+// reference are kept as is.
+func expandWindows(s string, mapping func(string) string) string {
+	var b strings.Builder
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		j := i + 1
+		for j < len(s) && isNameChar(s[j]) {
+			j++
+		}
+		if j == len(s) || s[j] != '%' {
+			continue
+		}
+		b.WriteString(os.Expand(s[start:i], mapping))
+		if j == i+1 {
+			b.WriteByte('%')
+		} else {
+			b.WriteString(mapping(s[i+1 : j]))
+		}
+		start = j + 1
+		i = j
+	}
+	b.WriteString(os.Expand(s[start:], mapping))
+	return b.String()
+}
+
+// splitExpansion splits the reference inside `${...}` into the name of the
+// variable, the operator, if any, and the word following it.
+func splitExpansion(ref string) (name, op, word string) {
+	i := 0
+	for i < len(ref) && isNameChar(ref[i]) {
+		i++
+	}
+	for _, op := range expansionOperators {
+		if strings.HasPrefix(ref[i:], op) {
+			return ref[:i], op, ref[i+len(op):]
+		}
+	}
+	return ref, "", ""
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,45 @@
+package env
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"sort"
+)
+
+// Fingerprint returns a stable hash of the configuration in v, a struct or a
+// pointer to a struct, e.g. to detect config drift between replicas or to
+// decide whether a restart is needed after a reload.
+//
+// The hash covers the variables returned by MarshalVars, sorted by key, so
+// that it does not depend on the order of the fields. The values of
+// sensitive fields are hashed rather than included, so that the fingerprint
+// can be logged or exposed safely while still changing with them.
+func Fingerprint(v interface{}, opts ...Option) (string, error) {
+	vars, err := MarshalVars(v, append(opts, WithUnredacted())...)
+	if err != nil {
+		return "", err
+	}
+	sort.SliceStable(vars, func(i, j int) bool {
+		return vars[i].Key < vars[j].Key
+	})
+	h := sha256.New()
+	for _, v := range vars {
+		value := v.Value
+		if v.Sensitive {
+			sum := sha256.Sum256([]byte(value))
+			value = hex.EncodeToString(sum[:])
+		}
+		writeString(h, v.Key)
+		writeString(h, value)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeString writes s to h preceded by its length, so that the boundaries
+// between strings cannot be moved without changing the hash.
+func writeString(h hash.Hash, s string) {
+	_ = binary.Write(h, binary.BigEndian, uint64(len(s)))
+	h.Write([]byte(s))
+}
@@ -0,0 +1,65 @@
+package env
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	type config struct {
+		Host     string        `env:"HOST"`
+		Port     int           `env:"PORT" envDefault:"8080"`
+		Timeout  time.Duration `env:"TIMEOUT"`
+		Labels   map[string]string
+		Password string `env:"PASSWORD,sensitive"`
+	}
+	type reordered struct {
+		Password string        `env:"PASSWORD,sensitive"`
+		Timeout  time.Duration `env:"TIMEOUT"`
+		Port     int           `env:"PORT" envDefault:"8080"`
+		Host     string        `env:"HOST"`
+	}
+	cfg := config{Host: "localhost", Timeout: time.Second, Password: "hunter2"}
+
+	fp, err := Fingerprint(cfg)
+	require.NoError(t, err)
+	assert.Len(t, fp, 64)
+
+	same, err := Fingerprint(&reordered{Password: "hunter2", Timeout: time.Second, Port: 8080, Host: "localhost"})
+	require.NoError(t, err)
+	assert.Equal(t, fp, same)
+
+	for name, other := range map[string]config{
+		"value":     {Host: "example.com", Timeout: time.Second, Password: "hunter2"},
+		"sensitive": {Host: "localhost", Timeout: time.Second, Password: "hunter3"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := Fingerprint(other)
+			require.NoError(t, err)
+			assert.NotEqual(t, fp, got)
+		})
+	}
+
+	prefixed, err := Fingerprint(cfg, WithPrefix("APP_"))
+	require.NoError(t, err)
+	assert.NotEqual(t, fp, prefixed)
+
+	type pair struct {
+		A string `env:"A"`
+		B string `env:"B"`
+	}
+	split, err := Fingerprint(pair{A: "x", B: "y"})
+	require.NoError(t, err)
+	type single struct {
+		A string `env:"A"`
+	}
+	joined, err := Fingerprint(single{A: "x\x00B\x00y"})
+	require.NoError(t, err)
+	assert.NotEqual(t, split, joined)
+
+	_, err = Fingerprint("nope")
+	assert.EqualError(t, err, "env: expected a struct or a pointer to a struct, got string")
+}
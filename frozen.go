@@ -0,0 +1,169 @@
+package env
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Frozen is a read-only view of a parsed configuration. Parse still targets
+// the writable struct; freezing it afterwards prevents code holding the
+// Frozen value, typically a package-level variable, from mutating the
+// configuration after startup.
+//
+// Get returns a deep copy, so changes made to its result, including to
+// slices, maps and pointed-to values, are never seen by other callers.
+// Structs of other packages without env tags, such as time.Time or url.URL,
+// are copied as plain values, without copying what they point to, and Lazy
+// values keep sharing their state. Channels and functions are shared, and
+// cyclic values are not supported.
+type Frozen[T any] struct {
+	value T
+}
+
+// Freeze returns a read-only view of a deep copy of v.
+func Freeze[T any](v T) Frozen[T] {
+	return Frozen[T]{value: deepCopy(v)}
+}
+
+// ParseFrozen parses a new T with the given options and freezes it.
+func ParseFrozen[T any](opts ...Option) (Frozen[T], error) {
+	var cfg T
+	if err := Parse(&cfg, opts...); err != nil {
+		return Frozen[T]{}, err
+	}
+	return Frozen[T]{value: cfg}, nil
+}
+
+// Get returns a deep copy of the frozen value.
+func (f Frozen[T]) Get() T {
+	return deepCopy(f.value)
+}
+
+// Clone returns a deep copy of v, typically a pointer to a parsed
+// configuration, so that reloading code can populate and validate a
+// candidate configuration before swapping it with the live one, without
+// the two sharing slices, maps or pointed-to values. The same values as
+// with Frozen are shared, and cyclic values are not supported.
+func Clone(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return copyValue(reflect.ValueOf(v)).Interface()
+}
+
+func deepCopy[T any](v T) T {
+	src := reflect.ValueOf(&v).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	dst.Set(copyValue(src))
+	return dst.Interface().(T)
+}
+
+// copyValue returns a deep copy of v, walking the structs of the package
+// declaring its type.
+func copyValue(v reflect.Value) reflect.Value {
+	return copier{pkg: packageOf(v.Type())}.copy(v)
+}
+
+// packageOf returns the package declaring t, or the type of its elements.
+func packageOf(t reflect.Type) string {
+	for t.Name() == "" {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return ""
+		}
+	}
+	return t.PkgPath()
+}
+
+// nolint: gochecknoglobals
+var envPackage = reflect.TypeOf(Options{}).PkgPath()
+
+// copier deep-copies configurations declared in pkg.
+type copier struct {
+	pkg string
+}
+
+// walks reports whether the fields of structs of type t are copied one by
+// one: those of the configuration's package, of this one, unnamed ones and
+// those with env tags. Other structs are opaque values, whose internals,
+// such as the *time.Location of a time.Time, are not duplicated.
+func (c copier) walks(t reflect.Type) bool {
+	if reflect.PtrTo(t).Implements(lazierType) {
+		return false
+	}
+	if pkg := t.PkgPath(); t.Name() == "" || pkg == c.pkg || pkg == envPackage {
+		return true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag
+		if _, ok := tag.Lookup("env"); ok {
+			return true
+		}
+		if _, ok := tag.Lookup("envPrefix"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c copier) copy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.New(v.Type().Elem())
+		n.Elem().Set(c.copy(v.Elem()))
+		return n
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.New(v.Type()).Elem()
+		n.Set(c.copy(v.Elem()))
+		return n
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			n.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return n
+	case reflect.Array:
+		n := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			n.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return n
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			n.SetMapIndex(c.copy(iter.Key()), c.copy(iter.Value()))
+		}
+		return n
+	case reflect.Struct:
+		n := reflect.New(v.Type()).Elem()
+		n.Set(v)
+		if !c.walks(v.Type()) {
+			return n
+		}
+		for i := 0; i < n.NumField(); i++ {
+			field := n.Field(i)
+			if !field.CanSet() {
+				field = reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+			}
+			field.Set(c.copy(field))
+		}
+		return n
+	default:
+		return v
+	}
+}
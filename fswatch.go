@@ -0,0 +1,74 @@
+package env
+
+import (
+	"sync"
+	"time"
+)
+
+// WithFileWatch makes Watch reload the configuration as soon as one of the
+// files loaded by fields with the `file` option changes, rather than at the
+// next interval, e.g. to pick up rotated TLS keys and tokens without delay.
+// Files are watched with inotify on Linux, and polled every second on other
+// systems.
+func WithFileWatch() Option {
+	return func(o *Options) {
+		o.WatchFiles = true
+	}
+}
+
+// fileWatcher notifies of changes to a set of files.
+type fileWatcher interface {
+	// watch replaces the watched files with paths.
+	watch(paths []string)
+
+	// changes receives a value after watched files changed.
+	changes() <-chan struct{}
+
+	close() error
+}
+
+// settleDelay is how long watched files must be left alone before a
+// reload, so that files are not read while being written.
+const settleDelay = 50 * time.Millisecond
+
+// changeNotifier sends a value on its channel once changes have settled, so
+// that bursts of changes result in a single reload.
+type changeNotifier struct {
+	ch chan struct{}
+
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+func newChangeNotifier() *changeNotifier {
+	return &changeNotifier{ch: make(chan struct{}, 1)}
+}
+
+// changed records a change, delaying the notification of earlier ones.
+func (n *changeNotifier) changed() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.timer != nil {
+		n.timer.Reset(settleDelay)
+		return
+	}
+	n.timer = time.AfterFunc(settleDelay, func() {
+		select {
+		case n.ch <- struct{}{}:
+		default:
+		}
+	})
+}
+
+func (n *changeNotifier) changes() <-chan struct{} {
+	return n.ch
+}
+
+// stop cancels the pending notification, if any.
+func (n *changeNotifier) stop() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.timer != nil {
+		n.timer.Stop()
+	}
+}
@@ -0,0 +1,125 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	// fileMask selects the events reported for watched files, followed
+	// through symbolic links: writes, metadata changes, and the file being
+	// replaced.
+	fileMask = syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_ATTRIB |
+		syscall.IN_MOVE_SELF | syscall.IN_DELETE_SELF
+
+	// dirMask selects the events reported for the directories of watched
+	// files, such as the renaming of the ..data link by which Kubernetes
+	// swaps the content of Secret and ConfigMap volumes.
+	dirMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+)
+
+type inotifyWatcher struct {
+	fd int
+	f  *os.File
+	*changeNotifier
+
+	mu sync.Mutex
+	// names holds, for the watch descriptors of directories, the names of
+	// the entries whose events matter, and nil for files.
+	names map[int32]map[string]bool
+}
+
+func newFileWatcher() (fileWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	w := &inotifyWatcher{
+		fd:             fd,
+		f:              os.NewFile(uintptr(fd), "inotify"),
+		changeNotifier: newChangeNotifier(),
+		names:          map[int32]map[string]bool{},
+	}
+	go w.read()
+	return w, nil
+}
+
+// read notifies of the events read from the inotify instance, until it is
+// closed.
+func (w *inotifyWatcher) read() {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := w.f.Read(buf)
+		if err != nil {
+			return
+		}
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			nameStart := off + syscall.SizeofInotifyEvent
+			name := strings.TrimRight(string(buf[nameStart:nameStart+int(ev.Len)]), "\x00")
+			off = nameStart + int(ev.Len)
+			if w.matters(ev.Wd, ev.Mask, name) {
+				w.changed()
+			}
+		}
+	}
+}
+
+// matters reports whether an event may change the content of a watched
+// file.
+func (w *inotifyWatcher) matters(wd int32, mask uint32, name string) bool {
+	if mask&syscall.IN_IGNORED != 0 {
+		// the watch was removed, by watch or because the file is gone,
+		// in which case IN_DELETE_SELF was reported first
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	names, ok := w.names[wd]
+	if !ok {
+		return false
+	}
+	return names == nil || names[name]
+}
+
+// watch replaces all the watches, as the ones of files that were replaced
+// are dropped by the kernel. Symbolic links are followed, and the
+// directories holding the files are watched for the files, or the ..data
+// link of Kubernetes volumes, being replaced. Files that do not exist are
+// skipped: they are watched again after the next reload.
+func (w *inotifyWatcher) watch(paths []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for wd := range w.names {
+		_, _ = syscall.InotifyRmWatch(w.fd, uint32(wd))
+	}
+	w.names = map[int32]map[string]bool{}
+	dirs := map[string]map[string]bool{}
+	for _, path := range paths {
+		if wd, err := syscall.InotifyAddWatch(w.fd, path, fileMask); err == nil {
+			w.names[int32(wd)] = nil
+		}
+		dir, name := filepath.Split(filepath.Clean(path))
+		if dirs[dir] == nil {
+			dirs[dir] = map[string]bool{"..data": true}
+		}
+		dirs[dir][name] = true
+	}
+	for dir, names := range dirs {
+		if dir == "" {
+			dir = "."
+		}
+		if wd, err := syscall.InotifyAddWatch(w.fd, dir, dirMask|syscall.IN_ONLYDIR); err == nil {
+			w.names[int32(wd)] = names
+		}
+	}
+}
+
+func (w *inotifyWatcher) close() error {
+	w.stop()
+	return w.f.Close()
+}
@@ -0,0 +1,91 @@
+//go:build !linux
+
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// pollInterval is how often files are checked for changes on systems
+// without inotify.
+const pollInterval = time.Second
+
+// fileState is what tells a file changed: its metadata, and the file it
+// resolves to through symbolic links, which changes when Kubernetes swaps
+// the content of Secret and ConfigMap volumes.
+type fileState struct {
+	target  string
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+type pollWatcher struct {
+	*changeNotifier
+	done chan struct{}
+
+	mu    sync.Mutex
+	files map[string]fileState
+}
+
+func newFileWatcher() (fileWatcher, error) {
+	w := &pollWatcher{
+		changeNotifier: newChangeNotifier(),
+		done:           make(chan struct{}),
+		files:          map[string]fileState{},
+	}
+	go w.poll()
+	return w, nil
+}
+
+func stat(path string) fileState {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fileState{}
+	}
+	fi, err := os.Stat(target)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{target: target, modTime: fi.ModTime(), size: fi.Size(), exists: true}
+}
+
+// poll notifies when the state of a watched file changes, until the watcher
+// is closed.
+func (w *pollWatcher) poll() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+		w.mu.Lock()
+		for path, prev := range w.files {
+			if cur := stat(path); cur != prev {
+				w.files[path] = cur
+				w.changed()
+			}
+		}
+		w.mu.Unlock()
+	}
+}
+
+func (w *pollWatcher) watch(paths []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.files = make(map[string]fileState, len(paths))
+	for _, path := range paths {
+		w.files[path] = stat(path)
+	}
+}
+
+func (w *pollWatcher) close() error {
+	w.stop()
+	close(w.done)
+	return nil
+}
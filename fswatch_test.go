@@ -0,0 +1,108 @@
+package env
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchFiles(t *testing.T) {
+	type config struct {
+		Token string `env:"TOKEN,file"`
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(file, []byte("a"), 0o600))
+	src := MapSource{"TOKEN": file}
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(src)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tokens := make(chan string)
+	go Watch(ctx, &cfg, time.Hour, func(old, new config, changes []Change) {
+		tokens <- new.Token
+	}, WithSource(src), WithFileWatch())
+
+	expect := func(want string) {
+		t.Helper()
+		select {
+		case got := <-tokens:
+			assert.Equal(t, want, got)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no change to %q", want)
+		}
+	}
+
+	require.NoError(t, os.WriteFile(file, []byte("b"), 0o600))
+	expect("b")
+
+	// replace the file, as editors and atomic writers do
+	tmp := filepath.Join(dir, "token.tmp")
+	require.NoError(t, os.WriteFile(tmp, []byte("c"), 0o600))
+	require.NoError(t, os.Rename(tmp, file))
+	expect("c")
+
+	require.NoError(t, os.WriteFile(file, []byte("d"), 0o600))
+	expect("d")
+}
+
+func TestWatchFilesKubernetes(t *testing.T) {
+	type config struct {
+		Token string `env:"TOKEN,file"`
+	}
+	// lay the volume out as Kubernetes does: the file is a link to
+	// ..data/token, ..data being a link to a timestamped directory
+	dir := t.TempDir()
+	writeVersion := func(version, token string) {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, version), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, version, "token"), []byte(token), 0o600))
+	}
+	writeVersion("..2024_01", "a")
+	require.NoError(t, os.Symlink("..2024_01", filepath.Join(dir, "..data")))
+	require.NoError(t, os.Symlink("..data/token", filepath.Join(dir, "token")))
+
+	var lookups int32
+	src := SourceFunc(func(key string) (string, bool) {
+		atomic.AddInt32(&lookups, 1)
+		return filepath.Join(dir, "token"), true
+	})
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(src)))
+	assert.Equal(t, "a", cfg.Token)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tokens := make(chan string)
+	go Watch(ctx, &cfg, time.Hour, func(old, new config, changes []Change) {
+		tokens <- new.Token
+	}, WithSource(src), WithFileWatch())
+
+	// wait for the initial reload, then swap ..data atomically
+	for atomic.LoadInt32(&lookups) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	writeVersion("..2024_02", "b")
+	require.NoError(t, os.Symlink("..2024_02", filepath.Join(dir, "..data_tmp")))
+	require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "..2024_01")))
+
+	select {
+	case got := <-tokens:
+		assert.Equal(t, "b", got)
+	case <-time.After(5 * time.Second):
+		t.Fatal("rotation not detected")
+	}
+
+	// nothing changes anymore, so the file is not read again
+	time.Sleep(100 * time.Millisecond)
+	n := atomic.LoadInt32(&lookups)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, n, atomic.LoadInt32(&lookups))
+}
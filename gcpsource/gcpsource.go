@@ -0,0 +1,117 @@
+// Package gcpsource provides an env.Source backed by Google Cloud Secret
+// Manager.
+//
+// To keep the Google Cloud libraries out of the dependencies of applications
+// that do not need them, the package reads secrets through the one-method
+// Client interface, which takes a few lines to implement on top of the
+// Secret Manager client:
+//
+//	type client struct{ *secretmanager.Client }
+//
+//	func (c client) AccessSecretVersion(ctx context.Context, name string) ([]byte, error) {
+//		resp, err := c.Client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{Name: name})
+//		if status.Code(err) == codes.NotFound {
+//			return nil, gcpsource.ErrNotFound
+//		}
+//		if err != nil {
+//			return nil, err
+//		}
+//		return resp.Payload.Data, nil
+//	}
+package gcpsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/conradludgate/env/v6"
+	"github.com/conradludgate/env/v6/internal/memo"
+)
+
+// ErrNotFound is returned, possibly wrapped, by clients when a secret or
+// version does not exist, in which case the variable is considered unset.
+var ErrNotFound = errors.New("gcpsource: not found")
+
+// Client accesses secret versions.
+type Client interface {
+	// AccessSecretVersion returns the payload of the secret version with
+	// the given resource name, e.g.
+	// projects/my-project/secrets/DB_PASSWORD/versions/latest.
+	AccessSecretVersion(ctx context.Context, name string) ([]byte, error)
+}
+
+// Source is an env.Source resolving each variable from the secret named
+// after it. Values are fetched on first use and cached, so a Source is meant
+// to be used for a single Parse, or a few at startup.
+type Source struct {
+	// Client accesses the secrets.
+	Client Client
+
+	// Project is the ID of the project holding the secrets.
+	Project string
+
+	// Name maps the name of a variable to the ID of its secret. Defaults to
+	// the variable name.
+	Name func(key string) string
+
+	// Version is the version of the secrets to read. Defaults to "latest".
+	Version string
+
+	// Versions pins the version of the secrets of some variables, keyed by
+	// variable name, overriding Version.
+	Versions map[string]string
+
+	cache memo.Cache[result]
+}
+
+type result struct {
+	value string
+	ok    bool
+}
+
+var _ env.ContextSource = (*Source)(nil)
+
+// New returns a Source reading the latest version of the secrets of project.
+func New(client Client, project string) *Source {
+	return &Source{Client: client, Project: project}
+}
+
+// Lookup retrieves the value of the variable named by key. Errors other than
+// ErrNotFound are ignored; env.Parse reports them through LookupContext.
+func (s *Source) Lookup(key string) (string, bool) {
+	value, ok, _ := s.LookupContext(context.Background(), key)
+	return value, ok
+}
+
+// LookupContext retrieves the value of the variable named by key. Lookups
+// of different variables run concurrently, e.g. with env.WithConcurrency,
+// while concurrent lookups of the same variable share one fetch.
+func (s *Source) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	r, err := s.cache.Get(ctx, key, func(ctx context.Context) (result, error) {
+		payload, err := s.Client.AccessSecretVersion(ctx, s.resourceName(key))
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return result{}, err
+		}
+		return result{value: string(payload), ok: err == nil}, nil
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return r.value, r.ok, nil
+}
+
+func (s *Source) resourceName(key string) string {
+	secret := key
+	if s.Name != nil {
+		secret = s.Name(key)
+	}
+	version := s.Versions[key]
+	if version == "" {
+		version = s.Version
+	}
+	if version == "" {
+		version = "latest"
+	}
+	return fmt.Sprintf("projects/%s/secrets/%s/versions/%s", s.Project, secret, version)
+}
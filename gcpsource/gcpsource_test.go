@@ -0,0 +1,78 @@
+package gcpsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/conradludgate/env/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	versions map[string]string
+	names    []string
+	err      error
+}
+
+func (c *fakeClient) AccessSecretVersion(_ context.Context, name string) ([]byte, error) {
+	c.names = append(c.names, name)
+	if c.err != nil {
+		return nil, c.err
+	}
+	v, ok := c.versions[name]
+	if !ok {
+		return nil, fmt.Errorf("secret %s: %w", name, ErrNotFound)
+	}
+	return []byte(v), nil
+}
+
+type config struct {
+	Password string `env:"DB_PASSWORD,required"`
+	APIKey   string `env:"API_KEY"`
+	Port     int    `env:"PORT" envDefault:"3000"`
+}
+
+func TestSource(t *testing.T) {
+	client := &fakeClient{versions: map[string]string{
+		"projects/p/secrets/DB_PASSWORD/versions/latest": "secret",
+		"projects/p/secrets/API_KEY/versions/latest":     "key",
+	}}
+
+	var cfg config
+	require.NoError(t, env.Parse(&cfg, env.WithSource(New(client, "p"))))
+	assert.Equal(t, config{Password: "secret", APIKey: "key", Port: 3000}, cfg)
+}
+
+func TestSourceVersions(t *testing.T) {
+	client := &fakeClient{versions: map[string]string{
+		"projects/p/secrets/db-password/versions/3": "old",
+		"projects/p/secrets/api-key/versions/7":     "key",
+	}}
+	src := New(client, "p")
+	src.Name = func(key string) string {
+		return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+	}
+	src.Version = "7"
+	src.Versions = map[string]string{"DB_PASSWORD": "3"}
+
+	var cfg config
+	require.NoError(t, env.Parse(&cfg, env.WithSource(src)))
+	assert.Equal(t, config{Password: "old", APIKey: "key", Port: 3000}, cfg)
+
+	calls := len(client.names)
+	_, ok := src.Lookup("DB_PASSWORD")
+	assert.True(t, ok)
+	assert.Len(t, client.names, calls)
+}
+
+func TestSourceError(t *testing.T) {
+	client := &fakeClient{err: errors.New("permission denied")}
+
+	var cfg config
+	err := env.Parse(&cfg, env.WithSource(New(client, "p")))
+	assert.EqualError(t, err, `env: could not look up "DB_PASSWORD": permission denied`)
+}
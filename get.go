@@ -0,0 +1,64 @@
+package env
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// Get looks up the variable named by key and parses it as a T, with the same
+// parsers as Parse, custom ones given with WithFuncs included. It fails if
+// the variable is not set, making it a replacement for the ad-hoc strconv
+// calls reading one-off variables:
+//
+//	port, err := env.Get[int]("PORT")
+//
+// The options of Parse apply, e.g. WithPrefix or WithSource.
+func Get[T any](key string, opts ...Option) (T, error) {
+	v, _, err := get[T](key, true, opts)
+	return v, err
+}
+
+// GetOr is like Get, except that it returns def if the variable is not set
+// or cannot be parsed, in which case the error is passed to the warning hook
+// set with WithWarningHook.
+func GetOr[T any](key string, def T, opts ...Option) T {
+	v, p, err := get[T](key, false, opts)
+	if err != nil {
+		p.warn(err)
+		return def
+	}
+	if len(p.values) == 0 {
+		// The variable is not set.
+		return def
+	}
+	return v
+}
+
+// MustGet is like Get, except that it panics with the error instead of
+// returning it.
+func MustGet[T any](key string, opts ...Option) T {
+	v, err := Get[T](key, opts...)
+	Must(err)
+	return v
+}
+
+// get parses the variable named by key into the field of a struct built for
+// the purpose, and returns the parser used.
+func get[T any](key string, required bool, opts []Option) (T, *parser, error) {
+	tag := key
+	if required {
+		tag += ",required"
+	}
+	typ := reflect.StructOf([]reflect.StructField{{
+		Name: "Value",
+		Type: reflect.TypeOf((*T)(nil)).Elem(),
+		Tag:  reflect.StructTag("env:" + strconv.Quote(tag)),
+	}})
+	ptr := reflect.New(typ)
+	p := newParser(opts)
+	if err := p.parse(ptr.Interface()); err != nil {
+		var zero T
+		return zero, p, err
+	}
+	return ptr.Elem().Field(0).Interface().(T), p, nil
+}
@@ -0,0 +1,75 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	src := WithSource(MapSource{
+		"PORT":    "8080",
+		"TIMEOUT": "5s",
+		"HOSTS":   "a,b",
+		"APP_ID":  "42",
+		"BAD":     "x",
+	})
+
+	port, err := Get[int]("PORT", src)
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port)
+
+	timeout, err := Get[time.Duration]("TIMEOUT", src)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, timeout)
+
+	hosts, err := Get[[]string]("HOSTS", src)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, hosts)
+
+	id, err := Get[int]("ID", src, WithPrefix("APP_"))
+	require.NoError(t, err)
+	assert.Equal(t, 42, id)
+
+	_, err = Get[int]("MISSING", src)
+	assert.EqualError(t, err, `env: required environment variable "MISSING" is not set`)
+
+	_, err = Get[int]("BAD", src)
+	assert.Error(t, err)
+}
+
+func TestGetCustomParser(t *testing.T) {
+	type level int
+	funcs := WithFuncs(map[reflect.Type]ParserFunc{
+		reflect.TypeOf(level(0)): func(v string) (interface{}, error) {
+			if v == "debug" {
+				return level(1), nil
+			}
+			return nil, errors.New("unknown level")
+		},
+	})
+	l, err := Get[level]("LEVEL", funcs, WithSource(MapSource{"LEVEL": "debug"}))
+	require.NoError(t, err)
+	assert.Equal(t, level(1), l)
+}
+
+func TestGetOr(t *testing.T) {
+	src := WithSource(MapSource{"PORT": "8080", "BAD": "x"})
+	assert.Equal(t, 8080, GetOr("PORT", 3000, src))
+	assert.Equal(t, 3000, GetOr("MISSING", 3000, src))
+
+	var warnings []error
+	hook := WithWarningHook(func(err error) { warnings = append(warnings, err) })
+	assert.Equal(t, 3000, GetOr("BAD", 3000, src, hook))
+	assert.Len(t, warnings, 1)
+}
+
+func TestMustGet(t *testing.T) {
+	src := WithSource(MapSource{"PORT": "8080"})
+	assert.Equal(t, 8080, MustGet[int]("PORT", src))
+	assert.Panics(t, func() { MustGet[int]("MISSING", src) })
+}
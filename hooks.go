@@ -0,0 +1,85 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// AfterParser is implemented by configuration structs that check or derive
+// their fields once Parse populated them, e.g. to validate constraints
+// involving several fields next to the struct rather than in every caller.
+//
+// AfterParse is called on the structs given to Parse and on the nested
+// structs it populates, nested ones first, once all the fields, expanded
+// ones included, are set. Parse fails with the first error returned.
+type AfterParser interface {
+	AfterParse() error
+}
+
+// Defaulter is implemented by configuration structs whose defaults cannot
+// be expressed as `envDefault` strings, such as maps, slices or computed
+// values. SetEnvDefaults is called on the structs given to Parse and on the
+// nested structs it populates before their fields are looked up, so that
+// the variables that are set override the defaults it sets.
+type Defaulter interface {
+	SetEnvDefaults()
+}
+
+// setDefaults calls the SetEnvDefaults method of the struct ref, if any.
+func setDefaults(ref reflect.Value) {
+	if !ref.CanAddr() {
+		return
+	}
+	if d, ok := ref.Addr().Interface().(Defaulter); ok {
+		d.SetEnvDefaults()
+	}
+}
+
+// addHooks records the hooks implemented by the struct ref, whose fields
+// were just parsed.
+func (p *parser) addHooks(ref reflect.Value) {
+	if !ref.CanAddr() {
+		return
+	}
+	if h, ok := ref.Addr().Interface().(AfterParser); ok {
+		p.afterParse = append(p.afterParse, h)
+	}
+}
+
+// runHooks calls the hooks recorded while parsing.
+func (p *parser) runHooks() error {
+	for _, h := range p.afterParse {
+		if err := h.AfterParse(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithValidator makes Parse call validate with each struct it was given, as
+// a pointer, once it is populated and its AfterParse hooks returned, so that
+// parsing and validating a configuration is a single call. Errors are
+// wrapped, and can be unwrapped with errors.As, e.g. to the
+// validator.ValidationErrors of github.com/go-playground/validator, which
+// list every field that failed:
+//
+//	validate := validator.New()
+//	err := env.Parse(&cfg, env.WithValidator(validate.Struct))
+func WithValidator(validate func(v interface{}) error) Option {
+	return func(o *Options) {
+		o.Validator = validate
+	}
+}
+
+// validate calls the Validator, if any, with each of vs.
+func (p *parser) validate(vs []interface{}) error {
+	if p.Validator == nil {
+		return nil
+	}
+	for _, v := range vs {
+		if err := p.Validator(v); err != nil {
+			return fmt.Errorf("env: invalid configuration: %w", err)
+		}
+	}
+	return nil
+}
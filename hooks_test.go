@@ -0,0 +1,124 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hookedDatabase struct {
+	Host string `env:"HOST"`
+	Port int    `env:"PORT"`
+	Addr string
+
+	calls *[]string
+}
+
+func (d *hookedDatabase) AfterParse() error {
+	*d.calls = append(*d.calls, "database")
+	if d.Host == "" {
+		return errors.New("database host is required")
+	}
+	d.Addr = d.Host + ":" + strconv.Itoa(d.Port)
+	return nil
+}
+
+type hookedConfig struct {
+	Database hookedDatabase `envPrefix:"DB_"`
+	Home     string         `env:"HOME"`
+	Cache    string         `env:"CACHE" envDefault:"${HOME}/cache" envExpand:"true"`
+	CacheDir string
+
+	calls []string
+}
+
+func (c *hookedConfig) AfterParse() error {
+	c.calls = append(c.calls, "config")
+	c.CacheDir = c.Cache
+	return nil
+}
+
+func TestAfterParse(t *testing.T) {
+	cfg := hookedConfig{}
+	cfg.Database.calls = &cfg.calls
+	src := MapSource{"DB_HOST": "db", "DB_PORT": "5432", "HOME": "/home/app"}
+	require.NoError(t, Parse(&cfg, WithSource(src)))
+	assert.Equal(t, []string{"database", "config"}, cfg.calls)
+	assert.Equal(t, "db:5432", cfg.Database.Addr)
+	assert.Equal(t, "/home/app/cache", cfg.CacheDir)
+
+	cfg = hookedConfig{}
+	cfg.Database.calls = &cfg.calls
+	err := Parse(&cfg, WithSource(MapSource{}))
+	assert.EqualError(t, err, "database host is required")
+	assert.Equal(t, []string{"database"}, cfg.calls)
+}
+
+type defaultedConfig struct {
+	Labels map[string]string `env:"LABELS"`
+	Hosts  []string          `env:"HOSTS"`
+	Inner  struct {
+		Weights []int `env:"WEIGHTS"`
+	}
+}
+
+func (c *defaultedConfig) SetEnvDefaults() {
+	c.Labels = map[string]string{"team": "core"}
+	c.Hosts = []string{"localhost"}
+	c.Inner.Weights = []int{1, 2}
+}
+
+func TestSetEnvDefaults(t *testing.T) {
+	var cfg defaultedConfig
+	require.NoError(t, Parse(&cfg, WithSource(MapSource{"HOSTS": "a,b"})))
+	assert.Equal(t, map[string]string{"team": "core"}, cfg.Labels)
+	assert.Equal(t, []string{"a", "b"}, cfg.Hosts)
+	assert.Equal(t, []int{1, 2}, cfg.Inner.Weights)
+}
+
+type fieldErrors []string
+
+func (e fieldErrors) Error() string {
+	return fmt.Sprintf("invalid fields: %v", []string(e))
+}
+
+func TestValidator(t *testing.T) {
+	type config struct {
+		Port    int `env:"PORT"`
+		Workers int `env:"WORKERS"`
+	}
+	validate := func(v interface{}) error {
+		cfg := v.(*config)
+		var errs fieldErrors
+		if cfg.Port <= 0 {
+			errs = append(errs, "Port")
+		}
+		if cfg.Workers <= 0 {
+			errs = append(errs, "Workers")
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+		return nil
+	}
+
+	var cfg config
+	err := Parse(&cfg, WithSource(MapSource{"PORT": "0"}), WithValidator(validate))
+	assert.EqualError(t, err, "env: invalid configuration: invalid fields: [Port Workers]")
+	var fe fieldErrors
+	require.True(t, errors.As(err, &fe))
+	assert.Equal(t, fieldErrors{"Port", "Workers"}, fe)
+
+	err = Parse(&cfg, WithSource(MapSource{"PORT": "http"}), WithValidator(func(interface{}) error {
+		t.Error("validator called after a parse error")
+		return nil
+	}))
+	assert.Error(t, err)
+
+	require.NoError(t, Parse(&cfg, WithSource(MapSource{"PORT": "80", "WORKERS": "4"}), WithValidator(validate)))
+	assert.Equal(t, config{Port: 80, Workers: 4}, cfg)
+}
@@ -0,0 +1,114 @@
+package env
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Infer lists the variables of the Source starting with the configured
+// prefix, sorted by key, as Describe would list the fields of a struct
+// reading them, so that platform teams can audit the configuration a legacy
+// service consumes. The type of each variable is inferred from its value:
+// bool for true and false, int for integers, time.Duration for durations
+// such as 5s, url.URL for absolute URLs, and string otherwise. Field holds
+// an exported Go name derived from the key.
+//
+// The Source must be an Enumerator, as the process environment is. The
+// result can be turned into a draft struct with StructDefinition.
+func Infer(opts ...Option) ([]VarInfo, error) {
+	p := newParser(opts)
+	keys, err := Keys(p.Prefix, p.Source)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]VarInfo, 0, len(keys))
+	for _, key := range keys {
+		if key == p.Prefix {
+			continue
+		}
+		value, _, err := p.lookupSource(key)
+		if err != nil {
+			return nil, err
+		}
+		ownKey := strings.TrimPrefix(key, p.Prefix)
+		info := VarInfo{
+			FieldParams: FieldParams{Field: goFieldName(ownKey), OwnKey: ownKey, Key: key},
+			Type:        inferType(value),
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// StructDefinition returns the formatted declaration of a struct named name
+// with a field for each of the variables, tagged with their OwnKey, e.g. to
+// generate a configuration struct from the result of Infer. Fields are
+// named after Field, made unique. Importing the packages of the field types
+// is up to the caller.
+func StructDefinition(name string, vars []VarInfo) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	seen := map[string]int{}
+	for _, v := range vars {
+		fieldName := v.Field
+		if seen[v.Field]++; seen[v.Field] > 1 {
+			fieldName += strconv.Itoa(seen[v.Field])
+		}
+		fmt.Fprintf(&b, "\t%s %s `env:%q`\n", fieldName, v.Type, v.OwnKey)
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+// inferType returns the type of a field able to hold value.
+func inferType(value string) reflect.Type {
+	switch strings.ToLower(value) {
+	case "true", "false":
+		return reflect.TypeOf(false)
+	}
+	if _, err := strconv.Atoi(value); err == nil {
+		return reflect.TypeOf(0)
+	}
+	if _, err := time.ParseDuration(value); err == nil {
+		return reflect.TypeOf(time.Duration(0))
+	}
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.Host != "" {
+		return reflect.TypeOf(url.URL{})
+	}
+	return reflect.TypeOf("")
+}
+
+// nolint: gochecknoglobals
+var initialisms = map[string]bool{
+	"API": true, "DB": true, "DNS": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "SQL": true, "SSH": true,
+	"TCP": true, "TLS": true, "TTL": true, "UDP": true, "URI": true,
+	"URL": true, "UUID": true,
+}
+
+// goFieldName turns a key such as DATABASE_URL into an exported Go
+// identifier such as DatabaseURL.
+func goFieldName(key string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(key, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}) {
+		upper := strings.ToUpper(word)
+		if initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(upper[:1] + strings.ToLower(word[1:]))
+	}
+	name := b.String()
+	if name == "" || '0' <= name[0] && name[0] <= '9' {
+		name = "Var" + name
+	}
+	return name
+}
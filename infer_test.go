@@ -0,0 +1,70 @@
+package env
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfer(t *testing.T) {
+	src := MapSource{
+		"APP_PORT":         "8080",
+		"APP_DEBUG":        "TRUE",
+		"APP_TIMEOUT":      "5s",
+		"APP_DATABASE_URL": "postgres://db:5432/app",
+		"APP_NAME":         "svc",
+		"HOME":             "/root",
+	}
+	infos, err := Infer(WithPrefix("APP_"), WithSource(src))
+	require.NoError(t, err)
+
+	type inferred struct {
+		Field, OwnKey, Key string
+		Type               reflect.Type
+	}
+	var got []inferred
+	for _, info := range infos {
+		got = append(got, inferred{info.Field, info.OwnKey, info.Key, info.Type})
+	}
+	assert.Equal(t, []inferred{
+		{"DatabaseURL", "DATABASE_URL", "APP_DATABASE_URL", reflect.TypeOf(url.URL{})},
+		{"Debug", "DEBUG", "APP_DEBUG", reflect.TypeOf(false)},
+		{"Name", "NAME", "APP_NAME", reflect.TypeOf("")},
+		{"Port", "PORT", "APP_PORT", reflect.TypeOf(0)},
+		{"Timeout", "TIMEOUT", "APP_TIMEOUT", reflect.TypeOf(time.Duration(0))},
+	}, got)
+
+	_, err = Infer(WithSource(SourceFunc(func(string) (string, bool) { return "", false })))
+	assert.Equal(t, ErrNotEnumerable, err)
+}
+
+func TestStructDefinition(t *testing.T) {
+	infos, err := Infer(WithPrefix("APP_"), WithSource(MapSource{
+		"APP_LOG_LEVEL": "debug",
+		"APP_LOG-LEVEL": "info",
+		"APP_TIMEOUT":   "1m",
+	}))
+	require.NoError(t, err)
+	def, err := StructDefinition("Config", infos)
+	require.NoError(t, err)
+	assert.Equal(t, "type Config struct {\n"+
+		"\tLogLevel  string        `env:\"LOG-LEVEL\"`\n"+
+		"\tLogLevel2 string        `env:\"LOG_LEVEL\"`\n"+
+		"\tTimeout   time.Duration `env:\"TIMEOUT\"`\n"+
+		"}\n", string(def))
+}
+
+func TestGoFieldName(t *testing.T) {
+	for key, name := range map[string]string{
+		"DATABASE_URL": "DatabaseURL",
+		"HTTP_PORT":    "HTTPPort",
+		"log-level":    "LogLevel",
+		"2FA":          "Var2fa",
+	} {
+		assert.Equal(t, name, goFieldName(key), key)
+	}
+}
@@ -0,0 +1,65 @@
+// Package memo caches the values remote sources fetch by name, making
+// concurrent fetches of the same name share a single call.
+package memo
+
+import (
+	"context"
+	"sync"
+)
+
+// Cache holds the values fetched by name. Fetches of different names run
+// concurrently, while those of a name already being fetched wait for that
+// call and share its result. Errors are returned to every waiter but not
+// cached, so the next fetch tries again. The zero value is ready to use.
+type Cache[V any] struct {
+	mu      sync.Mutex
+	values  map[string]V
+	pending map[string]*call[V]
+}
+
+// call is a fetch in flight.
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// Get returns the value cached for name, or calls fetch to get it. A
+// caller waiting for another's fetch gives up when ctx is done.
+func (c *Cache[V]) Get(ctx context.Context, name string, fetch func(ctx context.Context) (V, error)) (V, error) {
+	c.mu.Lock()
+	if v, ok := c.values[name]; ok {
+		c.mu.Unlock()
+		return v, nil
+	}
+	if cl, ok := c.pending[name]; ok {
+		c.mu.Unlock()
+		select {
+		case <-cl.done:
+			return cl.value, cl.err
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		}
+	}
+	cl := &call[V]{done: make(chan struct{})}
+	if c.pending == nil {
+		c.pending = map[string]*call[V]{}
+	}
+	c.pending[name] = cl
+	c.mu.Unlock()
+
+	cl.value, cl.err = fetch(ctx)
+
+	c.mu.Lock()
+	delete(c.pending, name)
+	if cl.err == nil {
+		if c.values == nil {
+			c.values = map[string]V{}
+		}
+		c.values[name] = cl.value
+	}
+	c.mu.Unlock()
+	close(cl.done)
+	return cl.value, cl.err
+}
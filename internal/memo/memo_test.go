@@ -0,0 +1,82 @@
+package memo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	var c Cache[string]
+	var calls int32
+	fetch := func(ctx context.Context) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return "value", nil
+	}
+	for i := 0; i < 2; i++ {
+		v, err := c.Get(context.Background(), "A", fetch)
+		require.NoError(t, err)
+		assert.Equal(t, "value", v)
+	}
+	assert.Equal(t, int32(1), calls)
+
+	fail := errors.New("unavailable")
+	_, err := c.Get(context.Background(), "B", func(ctx context.Context) (string, error) {
+		return "", fail
+	})
+	assert.Equal(t, fail, err)
+	v, err := c.Get(context.Background(), "B", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, "value", v)
+}
+
+func TestGetConcurrent(t *testing.T) {
+	var c Cache[string]
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A is fetched once while in flight, and B alongside it: fetches are
+	// released once A and B have both started
+	var calls int32
+	started := make(chan struct{}, 3)
+	release := make(chan struct{})
+	fetch := func(name string) func(ctx context.Context) (string, error) {
+		return func(ctx context.Context) (string, error) {
+			atomic.AddInt32(&calls, 1)
+			started <- struct{}{}
+			select {
+			case <-release:
+				return name, nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 3)
+	get := func(i int, name string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.Get(ctx, name, fetch(name))
+			assert.NoError(t, err)
+			results[i] = v
+		}()
+	}
+	get(0, "A")
+	<-started
+	get(1, "A")
+	get(2, "B")
+	<-started
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []string{"A", "A", "B"}, results)
+	assert.Equal(t, int32(2), calls)
+}
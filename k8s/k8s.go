@@ -0,0 +1,95 @@
+// Package k8s generates Kubernetes manifests from configuration structs, so
+// that deployments stay in sync with the variables the code reads.
+package k8s
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/conradludgate/env/v6"
+	"github.com/conradludgate/env/v6/dotenv"
+)
+
+// Options configures the generated manifests.
+type Options struct {
+	// Secret is the name of the Secret holding the values of sensitive
+	// variables. Without it, sensitive variables are left out of the env
+	// block like they are left out of ConfigMaps.
+	Secret string
+
+	// Indent is prepended to every line of the env block, to paste it into
+	// a container spec.
+	Indent string
+}
+
+// Env generates the env block of a container for the struct pointed to by
+// v: variables with a default are set to it, required ones are set to an
+// empty value flagged with a comment, optional ones are commented out, and
+// sensitive ones refer to o.Secret. Descriptions from the `envDocs` tag are
+// written as comments. opts are the options v is parsed with, such as
+// env.WithPrefix.
+func Env(v interface{}, o Options, opts ...env.Option) ([]byte, error) {
+	vars, err := dotenv.ExampleVars(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for _, ev := range vars {
+		if ev.Sensitive && o.Secret == "" {
+			continue
+		}
+		writeComments(&buf, o.Indent, ev)
+		prefix := o.Indent
+		if !ev.Sensitive && !ev.HasDefault && !ev.Required {
+			prefix += "# "
+		}
+		fmt.Fprintf(&buf, "%s- name: %s\n", prefix, ev.Key)
+		switch {
+		case ev.Sensitive:
+			fmt.Fprintf(&buf, "%s  valueFrom:\n", prefix)
+			fmt.Fprintf(&buf, "%s    secretKeyRef:\n", prefix)
+			fmt.Fprintf(&buf, "%s      name: %s\n", prefix, o.Secret)
+			fmt.Fprintf(&buf, "%s      key: %s\n", prefix, ev.Key)
+		default:
+			fmt.Fprintf(&buf, "%s  value: %s\n", prefix, strconv.Quote(ev.Default))
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// ConfigMap generates a ConfigMap named name holding the variables of the
+// struct pointed to by v, following the same rules as Env. Sensitive
+// variables, which belong in a Secret, are left out.
+func ConfigMap(name string, v interface{}, opts ...env.Option) ([]byte, error) {
+	vars, err := dotenv.ExampleVars(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\ndata:\n", name)
+	for _, ev := range vars {
+		if ev.Sensitive {
+			continue
+		}
+		writeComments(&buf, "  ", ev)
+		prefix := "  "
+		if !ev.HasDefault && !ev.Required {
+			prefix += "# "
+		}
+		fmt.Fprintf(&buf, "%s%s: %s\n", prefix, ev.Key, strconv.Quote(ev.Default))
+	}
+	return buf.Bytes(), nil
+}
+
+func writeComments(buf *bytes.Buffer, indent string, ev dotenv.ExampleVar) {
+	if ev.Doc != "" {
+		for _, line := range strings.Split(strings.TrimRight(ev.Doc, "\n"), "\n") {
+			fmt.Fprintf(buf, "%s# %s\n", indent, line)
+		}
+	}
+	if ev.Required && !ev.HasDefault {
+		fmt.Fprintf(buf, "%s# required\n", indent)
+	}
+}
@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/conradludgate/env/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type config struct {
+	Port     int    `env:"PORT" envDefault:"3000" envDocs:"Port the HTTP server listens on."`
+	Host     string `env:"HOST,required"`
+	Password string `env:"DB_PASSWORD,required,sensitive"`
+	Debug    bool   `env:"DEBUG"`
+	Greeting string `env:"GREETING" envDefault:"say \"hi\""`
+}
+
+func TestEnv(t *testing.T) {
+	b, err := Env(&config{}, Options{Secret: "myapp", Indent: "  "})
+	require.NoError(t, err)
+	assert.Equal(t, `  # Port the HTTP server listens on.
+  - name: PORT
+    value: "3000"
+  # required
+  - name: HOST
+    value: ""
+  # required
+  - name: DB_PASSWORD
+    valueFrom:
+      secretKeyRef:
+        name: myapp
+        key: DB_PASSWORD
+  # - name: DEBUG
+  #   value: ""
+  - name: GREETING
+    value: "say \"hi\""
+`, string(b))
+
+	b, err = Env(config{}, Options{})
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "DB_PASSWORD")
+
+	b, err = Env(&config{}, Options{Secret: "myapp"}, env.WithPrefix("APP_"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "- name: APP_PORT\n")
+	assert.Contains(t, string(b), "key: APP_DB_PASSWORD\n")
+	assert.NotContains(t, string(b), " PORT")
+}
+
+func TestConfigMap(t *testing.T) {
+	b, err := ConfigMap("myapp", &config{})
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: myapp
+data:
+  # Port the HTTP server listens on.
+  PORT: "3000"
+  # required
+  HOST: ""
+  # DEBUG: ""
+  GREETING: "say \"hi\""
+`, string(b))
+
+	b, err = ConfigMap("myapp", &config{}, env.WithPrefix("APP_"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "  APP_PORT: \"3000\"\n")
+	assert.Contains(t, string(b), "  # APP_DEBUG: \"\"\n")
+
+	_, err = ConfigMap("myapp", 42)
+	assert.Error(t, err)
+}
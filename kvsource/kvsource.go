@@ -0,0 +1,138 @@
+// Package kvsource provides an env.Source backed by a key-value store such as
+// Consul or etcd, mapping the keys under a prefix to variable names, e.g.
+// myapp/db/password to DB_PASSWORD.
+//
+// To keep the store clients out of the dependencies of applications that do
+// not need them, the package lists keys through the one-method Client
+// interface, which takes a few lines to implement on top of the Consul or
+// etcd clients:
+//
+//	type consulClient struct{ kv *api.KV }
+//
+//	func (c consulClient) List(ctx context.Context, prefix string) (map[string]string, error) {
+//		pairs, _, err := c.kv.List(prefix, (&api.QueryOptions{}).WithContext(ctx))
+//		if err != nil {
+//			return nil, err
+//		}
+//		values := make(map[string]string, len(pairs))
+//		for _, p := range pairs {
+//			values[p.Key] = string(p.Value)
+//		}
+//		return values, nil
+//	}
+//
+//	type etcdClient struct{ *clientv3.Client }
+//
+//	func (c etcdClient) List(ctx context.Context, prefix string) (map[string]string, error) {
+//		resp, err := c.Get(ctx, prefix, clientv3.WithPrefix())
+//		if err != nil {
+//			return nil, err
+//		}
+//		values := make(map[string]string, len(resp.Kvs))
+//		for _, kv := range resp.Kvs {
+//			values[string(kv.Key)] = string(kv.Value)
+//		}
+//		return values, nil
+//	}
+package kvsource
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/conradludgate/env/v6"
+)
+
+// Client lists the entries of a key-value store.
+type Client interface {
+	// List returns the values of the keys starting with prefix, keyed by
+	// their full key.
+	List(ctx context.Context, prefix string) (map[string]string, error)
+}
+
+// Source is an env.Source resolving variables from the entries under a
+// prefix of a key-value store. The entries are listed once, on first use, so
+// a Source is meant to be used for a single Parse, or a few at startup.
+type Source struct {
+	// Client lists the entries.
+	Client Client
+
+	// Prefix is the prefix of the keys holding variables, e.g. "myapp/".
+	Prefix string
+
+	// Key maps a key, without Prefix, to a variable name. Defaults to
+	// upper-casing it and replacing slashes, dashes and dots with
+	// underscores.
+	Key func(key string) string
+
+	mu   sync.Mutex
+	vars env.MapSource
+}
+
+var (
+	_ env.ContextSource = (*Source)(nil)
+	_ env.Enumerator    = (*Source)(nil)
+)
+
+// New returns a Source reading the variables of the entries under prefix.
+func New(client Client, prefix string) *Source {
+	return &Source{Client: client, Prefix: prefix}
+}
+
+// Lookup retrieves the value of the variable named by key. Errors are
+// ignored; env.Parse reports them through LookupContext.
+func (s *Source) Lookup(key string) (string, bool) {
+	value, ok, _ := s.LookupContext(context.Background(), key)
+	return value, ok
+}
+
+// LookupContext retrieves the value of the variable named by key.
+func (s *Source) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	vars, err := s.load(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	value, ok := vars[key]
+	return value, ok, nil
+}
+
+// Keys returns the names of the variables starting with prefix.
+func (s *Source) Keys(prefix string) ([]string, error) {
+	vars, err := s.load(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return vars.Keys(prefix)
+}
+
+func (s *Source) load(ctx context.Context) (env.MapSource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.vars != nil {
+		return s.vars, nil
+	}
+	entries, err := s.Client.List(ctx, s.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	toKey := s.Key
+	if toKey == nil {
+		toKey = defaultKey
+	}
+	vars := env.MapSource{}
+	for key, value := range entries {
+		if rest := strings.TrimPrefix(key, s.Prefix); rest != "" && !strings.HasSuffix(rest, "/") {
+			vars[toKey(rest)] = value
+		}
+	}
+	s.vars = vars
+	return vars, nil
+}
+
+// nolint: gochecknoglobals
+var keyReplacer = strings.NewReplacer("/", "_", "-", "_", ".", "_")
+
+func defaultKey(key string) string {
+	return strings.ToUpper(keyReplacer.Replace(key))
+}
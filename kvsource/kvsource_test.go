@@ -0,0 +1,87 @@
+package kvsource
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/conradludgate/env/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	entries map[string]string
+	lists   int
+	err     error
+}
+
+func (c *fakeClient) List(_ context.Context, prefix string) (map[string]string, error) {
+	c.lists++
+	if c.err != nil {
+		return nil, c.err
+	}
+	values := map[string]string{}
+	for k, v := range c.entries {
+		if strings.HasPrefix(k, prefix) {
+			values[k] = v
+		}
+	}
+	return values, nil
+}
+
+type config struct {
+	Port     int    `env:"PORT"`
+	Password string `env:"DB_PASSWORD"`
+	Level    string `env:"LOG_LEVEL" envDefault:"info"`
+}
+
+func TestSource(t *testing.T) {
+	client := &fakeClient{entries: map[string]string{
+		"myapp/":            "",
+		"myapp/port":        "8080",
+		"myapp/db/password": "secret",
+		"other/port":        "1",
+	}}
+	src := New(client, "myapp/")
+
+	var cfg config
+	require.NoError(t, env.Parse(&cfg, env.WithSource(src)))
+	assert.Equal(t, config{Port: 8080, Password: "secret", Level: "info"}, cfg)
+	assert.Equal(t, 1, client.lists)
+
+	keys, err := env.Keys("", src)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DB_PASSWORD", "PORT"}, keys)
+}
+
+func TestSourceStrict(t *testing.T) {
+	client := &fakeClient{entries: map[string]string{
+		"myapp/app-port":     "8080",
+		"myapp/app-log.levl": "debug",
+	}}
+	src := New(client, "myapp/")
+
+	var cfg config
+	err := env.Parse(&cfg, env.WithSource(src), env.WithPrefix("APP_"), env.WithStrict())
+	assert.EqualError(t, err, `env: unknown environment variables with prefix "APP_": APP_LOG_LEVL`)
+}
+
+func TestSourceKey(t *testing.T) {
+	client := &fakeClient{entries: map[string]string{"myapp/Port": "8080"}}
+	src := New(client, "myapp/")
+	src.Key = func(key string) string { return "PORT" }
+
+	var cfg config
+	require.NoError(t, env.Parse(&cfg, env.WithSource(src)))
+	assert.Equal(t, 8080, cfg.Port)
+}
+
+func TestSourceError(t *testing.T) {
+	client := &fakeClient{err: errors.New("connection refused")}
+
+	var cfg config
+	err := env.Parse(&cfg, env.WithSource(New(client, "myapp/")))
+	assert.EqualError(t, err, `env: could not look up "PORT": connection refused`)
+}
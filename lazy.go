@@ -0,0 +1,122 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// Lazy holds a value looked up when first needed rather than by Parse, for
+// variables that are expensive to fetch, e.g. from a remote Source, or rarely
+// used. Parse only records how to resolve the field: the variable is read and
+// parsed by the first call to Get, whose result is kept for the following
+// ones. With the `reread` option, as in `env:"TOKEN,reread"`, every call to
+// Get reads the variable again.
+//
+// A Lazy[T] field takes the same tags as a T field, but its errors, missing
+// required variables included, are returned by Get instead of Parse.
+//
+// Lazy fields cannot be in groups: the `envGroup` tag is rejected, as the
+// constraints on groups are checked by Parse.
+//
+// Copies of a Lazy share its value. Get is safe for concurrent use.
+type Lazy[T any] struct {
+	state *lazyState[T]
+}
+
+type lazyState[T any] struct {
+	mu       sync.Mutex
+	resolve  func(ctx context.Context, field reflect.Value) error
+	reread   bool
+	resolved bool
+	value    T
+}
+
+// errNotParsed is returned by Get for Lazy values that Parse did not set up.
+var errNotParsed = errors.New("env: lazy value was not parsed")
+
+// Get resolves the value, if not done already, and returns it. If resolving
+// fails, the error is returned and the next call tries again.
+func (l Lazy[T]) Get() (T, error) {
+	return l.GetContext(context.Background())
+}
+
+// GetContext is Get with a context, passed to ContextSources.
+func (l Lazy[T]) GetContext(ctx context.Context) (T, error) {
+	var zero T
+	s := l.state
+	if s == nil {
+		return zero, errNotParsed
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.resolved && !s.reread {
+		return s.value, nil
+	}
+	var value T
+	if err := s.resolve(ctx, reflect.ValueOf(&value).Elem()); err != nil {
+		return zero, err
+	}
+	s.value, s.resolved = value, true
+	return value, nil
+}
+
+// MustGet is like Get but panics if the value cannot be resolved.
+func (l Lazy[T]) MustGet() T {
+	value, err := l.Get()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func (l *Lazy[T]) valueType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (l *Lazy[T]) setResolver(resolve func(ctx context.Context, field reflect.Value) error, reread bool) {
+	l.state = &lazyState[T]{resolve: resolve, reread: reread}
+}
+
+// lazier is implemented by pointers to Lazy values.
+type lazier interface {
+	valueType() reflect.Type
+	setResolver(resolve func(ctx context.Context, field reflect.Value) error, reread bool)
+}
+
+// nolint: gochecknoglobals
+var lazierType = reflect.TypeOf((*lazier)(nil)).Elem()
+
+func asLazy(field reflect.Value) lazier {
+	if !field.CanAddr() {
+		return nil
+	}
+	lz, _ := field.Addr().Interface().(lazier)
+	return lz
+}
+
+// parseLazy sets a Lazy field up to be parsed, when needed, as if it was a
+// field of the wrapped type.
+func (p *parser) parseLazy(prefix, path string, lz lazier, sf reflect.StructField) error {
+	params, err := p.fieldParams(prefix, path, sf)
+	if err != nil {
+		return err
+	}
+	if params.Key != "" {
+		p.lazy[params.Key] = true
+	}
+	opts := p.Options
+	opts.Report, opts.Strict, opts.Unset = nil, false, false
+	sf.Type = lz.valueType()
+	lz.setResolver(func(ctx context.Context, field reflect.Value) error {
+		q := newParserWith(opts)
+		q.ctx = ctx
+		value, _, err := q.get(prefix, path, sf)
+		if err != nil || value == "" {
+			return err
+		}
+		return q.setField(prefix, path, field, sf, value)
+	}, hasOption(sf, "reread"))
+	return nil
+}
@@ -0,0 +1,89 @@
+package env
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazy(t *testing.T) {
+	type config struct {
+		Token   Lazy[string]        `env:"TOKEN,required"`
+		Timeout Lazy[time.Duration] `env:"TIMEOUT" envDefault:"5s"`
+		Level   Lazy[string]        `env:"LEVEL,reread"`
+		Port    int                 `env:"PORT"`
+	}
+	src := &countingSource{
+		values: map[string]string{"APP_TOKEN": "secret", "APP_LEVEL": "info", "APP_PORT": "8080"},
+		calls:  map[string]int{},
+	}
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(src), WithPrefix("APP_")))
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, map[string]int{"APP_PORT": 1}, src.calls)
+
+	token, err := cfg.Token.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", token)
+	assert.Equal(t, "secret", cfg.Token.MustGet())
+	assert.Equal(t, 1, src.calls["APP_TOKEN"])
+	assert.Equal(t, 5*time.Second, cfg.Timeout.MustGet())
+
+	assert.Equal(t, "info", cfg.Level.MustGet())
+	src.values["APP_LEVEL"] = "debug"
+	assert.Equal(t, "debug", cfg.Level.MustGet())
+	assert.Equal(t, 2, src.calls["APP_LEVEL"])
+}
+
+func TestLazyError(t *testing.T) {
+	type config struct {
+		Token Lazy[string] `env:"TOKEN,required"`
+		Port  Lazy[int]    `env:"PORT"`
+	}
+	src := &countingSource{values: map[string]string{"PORT": "http"}, calls: map[string]int{}}
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(src)))
+
+	_, err := cfg.Token.Get()
+	assert.EqualError(t, err, `env: required environment variable "TOKEN" is not set`)
+	_, err = cfg.Port.Get()
+	assert.Error(t, err)
+	assert.Panics(t, func() { cfg.Token.MustGet() })
+
+	src.values["TOKEN"] = "secret"
+	assert.Equal(t, "secret", cfg.Token.MustGet())
+
+	type grouped struct {
+		Token Lazy[string] `env:"TOKEN" envGroup:"auth"`
+	}
+	err = Parse(&grouped{}, WithSource(src), WithRequireOneOf("auth"))
+	assert.EqualError(t, err, `env: envGroup not supported on Lazy field "Token"`)
+
+	var zero Lazy[string]
+	_, err = zero.Get()
+	assert.EqualError(t, err, "env: lazy value was not parsed")
+}
+
+func TestLazyMarshalAndDiff(t *testing.T) {
+	type config struct {
+		Token Lazy[string] `env:"TOKEN"`
+		Port  int          `env:"PORT"`
+	}
+	src := MapSource{"TOKEN": "secret", "PORT": "8080"}
+	var a, b config
+	require.NoError(t, Parse(&a, WithSource(src)))
+	require.NoError(t, Parse(&b, WithSource(src)))
+
+	vars, err := Marshal(&a)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"PORT": "8080"}, vars)
+
+	changes, err := Diff(a, b)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// Strict mode accepts the variables of Lazy fields.
+	require.NoError(t, Parse(&a, WithSource(MapSource{"APP_TOKEN": "secret"}), WithPrefix("APP_"), WithStrict()))
+}
@@ -0,0 +1,63 @@
+package env
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Live holds a configuration that can be replaced while it is being read,
+// e.g. by Watch, so that request handlers always see a complete
+// configuration, the latest one, without locking.
+//
+// Load does not copy the configuration: slices, maps and pointed-to values
+// are shared by all the callers and must not be modified. The zero Live
+// holds the zero T.
+type Live[T any] struct {
+	v atomic.Value
+}
+
+// NewLive returns a Live holding v.
+func NewLive[T any](v T) *Live[T] {
+	l := &Live[T]{}
+	l.Store(v)
+	return l
+}
+
+// ParseLive parses a new T with the given options and returns a Live
+// holding it, for Watch to update.
+func ParseLive[T any](opts ...Option) (*Live[T], error) {
+	v, err := ParseAs[T](opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewLive(v), nil
+}
+
+// Load returns the current configuration.
+func (l *Live[T]) Load() T {
+	v, _ := l.v.Load().(*T)
+	if v == nil {
+		var zero T
+		return zero
+	}
+	return *v
+}
+
+// Store replaces the configuration with v.
+func (l *Live[T]) Store(v T) {
+	l.v.Store(&v)
+}
+
+// Watch is like the Watch function, except that it watches the
+// configuration held by l and stores every new configuration in l before
+// calling onChange, which may be nil.
+func (l *Live[T]) Watch(ctx context.Context, interval time.Duration, onChange func(old, new T, changes []Change), opts ...Option) error {
+	cfg := l.Load()
+	return Watch(ctx, &cfg, interval, func(old, new T, changes []Change) {
+		l.Store(new)
+		if onChange != nil {
+			onChange(old, new, changes)
+		}
+	}, opts...)
+}
@@ -0,0 +1,55 @@
+package env
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLive(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT"`
+	}
+	var zero Live[config]
+	assert.Equal(t, config{}, zero.Load())
+
+	src := &mutableSource{vars: map[string]string{"PORT": "8080"}}
+	live, err := ParseLive[config](WithSource(src))
+	require.NoError(t, err)
+	assert.Equal(t, 8080, live.Load().Port)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{})
+	go live.Watch(ctx, time.Millisecond, func(old, new config, changes []Change) {
+		close(changed)
+	}, WithSource(src))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-changed:
+					return
+				default:
+					port := live.Load().Port
+					assert.True(t, port == 8080 || port == 9090)
+				}
+			}
+		}()
+	}
+	src.set("PORT", "9090")
+	<-changed
+	wg.Wait()
+	assert.Equal(t, 9090, live.Load().Port)
+
+	_, err = ParseLive[config](WithSource(MapSource{"PORT": "http"}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,67 @@
+package env
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Markdown writes to w a Markdown table of the variables Parse reads for v,
+// a struct or a pointer to a struct, with their type, whether they are
+// required, their default and their `envDocs` description, so that READMEs
+// and runbooks can be generated rather than maintained by hand, e.g. with a
+// small program run by go generate:
+//
+//	//go:generate go run ./internal/envdocs
+//
+//	func main() {
+//		f, err := os.Create("ENVIRONMENT.md")
+//		...
+//		err = env.Markdown(f, (*config.Config)(nil), env.WithPrefix("APP_"))
+//		...
+//	}
+func Markdown(w io.Writer, v interface{}, opts ...Option) error {
+	infos, err := Describe(v, opts...)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString("| Variable | Type | Required | Default | Description |\n")
+	bw.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, info := range infos {
+		var required, def string
+		if info.Required {
+			required = "yes"
+		}
+		if info.HasDefaultValue {
+			def = "*empty*"
+			if info.DefaultValue != "" {
+				def = codeSpan(info.DefaultValue)
+			}
+		}
+		cells := []string{
+			codeSpan(info.Key),
+			codeSpan(info.Type.String()),
+			required,
+			def,
+			markdownCell(info.Docs),
+		}
+		bw.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return bw.Flush()
+}
+
+// markdownCell makes text fit in a single table cell.
+func markdownCell(text string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(text), " "), "|", `\|`)
+}
+
+// codeSpan formats text as inline code in a table cell, using double
+// backticks if it contains backticks.
+func codeSpan(text string) string {
+	text = markdownCell(text)
+	if strings.Contains(text, "`") {
+		return "`` " + text + " ``"
+	}
+	return "`" + text + "`"
+}
@@ -0,0 +1,34 @@
+package env
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdown(t *testing.T) {
+	type config struct {
+		Port    int           `env:"PORT" envDefault:"8080" envDocs:"Port to listen on"`
+		DBURL   string        `env:"DATABASE_URL,required" envDocs:"Connection string,\n  with credentials"`
+		Timeout time.Duration `env:"TIMEOUT" envDefault:"5s"`
+		Hosts   []string      `env:"HOSTS" envDefault:"a|b" envDocs:"Hosts, e.g. a|b"`
+		Prompt  string        `env:"PROMPT" envDefault:"$ \x60cmd\x60"`
+		Suffix  string        `env:"SUFFIX" envDefault:""`
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Markdown(&buf, &config{}, WithPrefix("APP_")))
+	assert.Equal(t, "| Variable | Type | Required | Default | Description |\n"+
+		"| --- | --- | --- | --- | --- |\n"+
+		"| `APP_PORT` | `int` |  | `8080` | Port to listen on |\n"+
+		"| `APP_DATABASE_URL` | `string` | yes |  | Connection string, with credentials |\n"+
+		"| `APP_TIMEOUT` | `time.Duration` |  | `5s` |  |\n"+
+		"| `APP_HOSTS` | `[]string` |  | `a\\|b` | Hosts, e.g. a\\|b |\n"+
+		"| `APP_PROMPT` | `string` |  | `` $ `cmd` `` |  |\n"+
+		"| `APP_SUFFIX` | `string` |  | *empty* |  |\n", buf.String())
+
+	assert.EqualError(t, Markdown(&buf, "nope"), "env: expected a struct or a pointer to a struct, got string")
+}
@@ -0,0 +1,287 @@
+package env
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// Var is a variable produced by MarshalVars.
+type Var struct {
+	FieldParams
+
+	// Value is the value of the variable, as Parse reads it.
+	Value string
+}
+
+// Marshal returns the variables Parse would read v from, a struct or a
+// pointer to a struct, keyed by name. It uses the same tags as Parse, so
+// that prefixes, separators and encoding.TextMarshaler implementations are
+// honoured, and options such as WithPrefix.
+//
+// Zero-valued fields with an `envDefault` tag are written with their
+// default, while nil pointers, Lazy fields and fields with the `file`
+// option, whose value cannot be turned back into a path, are skipped. The
+// values of fields with the `base64` option or an `envEncoding` tag are
+// encoded.
+//
+// The values of sensitive fields, tagged with the `sensitive` option or of a
+// type implementing Sensitive, are masked with the Redactor so that they do
+// not leak into logs or generated files, unless WithUnredacted is given.
+//
+// Parsing the result of Marshal with WithUnredacted, e.g. with
+// WithSource(MapSource(m)), gives back a value equal to v for every
+// supported type, including slices and maps with custom separators or the
+// jsonArray or shellWords options, with the following exceptions:
+//
+//   - empty slices and maps are read back as nil;
+//   - types that implement encoding.TextUnmarshaler, or have a parser in the
+//     FuncMap, must format themselves as they are parsed, through
+//     encoding.TextMarshaler or fmt.Stringer;
+//   - map keys that contain the key/value separator cannot be told apart
+//     from it;
+//   - values of fields with the `envExpand` option are expanded again.
+func Marshal(v interface{}, opts ...Option) (map[string]string, error) {
+	vars, err := MarshalVars(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]string, len(vars))
+	for _, v := range vars {
+		m[v.Key] = v.Value
+	}
+	return m, nil
+}
+
+// ToEnviron is like Marshal, except that it returns KEY=value entries in the
+// order of the fields, as expected by exec.Cmd.Env, e.g. to start a child
+// process configured from a struct:
+//
+//	cmd.Env, err = env.ToEnviron(cfg, env.WithUnredacted())
+func ToEnviron(v interface{}, opts ...Option) ([]string, error) {
+	vars, err := MarshalVars(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	environ := make([]string, 0, len(vars))
+	for _, v := range vars {
+		environ = append(environ, v.Key+"="+v.Value)
+	}
+	return environ, nil
+}
+
+// Set sets the variables returned by Marshal in the process environment,
+// e.g. to seed it from a typed value in tests or bootstrap code. Sensitive
+// values are set as they are.
+func Set(v interface{}, opts ...Option) error {
+	vars, err := MarshalVars(v, append(opts, WithUnredacted())...)
+	if err != nil {
+		return err
+	}
+	for _, v := range vars {
+		if err := os.Setenv(v.Key, v.Value); err != nil {
+			return fmt.Errorf("env: %w", err)
+		}
+	}
+	return nil
+}
+
+// MarshalVars is like Marshal, except that it returns the variables in the
+// order of the fields, along with their tags.
+func MarshalVars(v interface{}, opts ...Option) ([]Var, error) {
+	ref := reflect.ValueOf(v)
+	if ref.Kind() == reflect.Ptr {
+		ref = ref.Elem()
+	}
+	if ref.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("env: expected a struct or a pointer to a struct, got %T", v)
+	}
+	if !ref.CanAddr() {
+		ptr := reflect.New(ref.Type())
+		ptr.Elem().Set(ref)
+		ref = ptr.Elem()
+	}
+	p := newParser(opts)
+	var vars []Var
+	if err := p.marshalStruct(&vars, p.Prefix, "", ref); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+func (p *parser) marshalStruct(vars *[]Var, prefix, path string, ref reflect.Value) error {
+	refType := ref.Type()
+	for i := 0; i < refType.NumField(); i++ {
+		sf := refType.Field(i)
+		field := ref.Field(i)
+		if !field.CanSet() {
+			if !p.Unexported {
+				continue
+			}
+			field = reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+		}
+		envPrefix := sf.Tag.Get("envPrefix")
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+			if field.Elem().Kind() == reflect.Struct && !isText(field) {
+				if err := p.marshalStruct(vars, prefix+envPrefix, path+sf.Name+".", field.Elem()); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		params, err := p.fieldParams(prefix, path+sf.Name, sf)
+		if err != nil {
+			return err
+		}
+		if params.Key == "" {
+			if field.Kind() == reflect.Struct {
+				if err := p.marshalStruct(vars, prefix+envPrefix, path+sf.Name+".", field); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if params.LoadFile || asLazy(field) != nil {
+			continue
+		}
+		if tr := asTracker(field); tr != nil {
+			field = tr.valuePtr().Elem()
+		}
+		var value string
+		if params.HasDefaultValue && field.IsZero() {
+			value = params.DefaultValue
+		} else if value, err = formatField(field, sf); err != nil {
+			return fmt.Errorf("env: field %q: %v", params.Field, err)
+		} else if value, err = encodeValue(params, value); err != nil {
+			return fmt.Errorf("env: field %q: %v", params.Field, err)
+		}
+		if !p.Unredacted {
+			value = p.redact(params, value)
+		}
+		*vars = append(*vars, Var{FieldParams: params, Value: value})
+	}
+	return nil
+}
+
+func isText(v reflect.Value) bool {
+	if _, ok := v.Interface().(encoding.TextMarshaler); ok {
+		return true
+	}
+	_, ok := v.Interface().(fmt.Stringer)
+	return ok
+}
+
+// formatField turns a field value into the string Parse reads it from.
+func formatField(v reflect.Value, sf reflect.StructField) (string, error) {
+	return formatValue(v, separators{
+		sep:   tagOr(sf, "envSeparator", ","),
+		kv:    tagOr(sf, "envKeyValSeparator", ":"),
+		inner: tagOr(sf, "envInnerSeparator", "|"),
+		json:  hasOption(sf, "jsonArray"),
+		shell: hasOption(sf, "shellWords"),
+	})
+}
+
+type separators struct {
+	sep, kv, inner string
+	// json writes slices as JSON arrays, for fields with the jsonArray
+	// option.
+	json bool
+	// shell writes slices as shell words, for fields with the shellWords
+	// option.
+	shell bool
+}
+
+func tagOr(sf reflect.StructField, tag, def string) string {
+	if v := sf.Tag.Get(tag); v != "" {
+		return v
+	}
+	return def
+}
+
+func formatValue(v reflect.Value, seps separators) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if v.CanAddr() {
+		v = v.Addr()
+	} else {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		v = ptr
+	}
+	if tm, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+	v = v.Elem()
+
+	// nested slices, and slices in maps, use the inner separator
+	innerSeps := seps
+	innerSeps.sep = seps.inner
+
+	switch v.Kind() {
+	case reflect.Slice:
+		parts := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			part, err := formatValue(v.Index(i), innerSeps)
+			if err != nil {
+				return "", err
+			}
+			switch {
+			case seps.shell:
+				part = quoteShellWord(part)
+			case !seps.json:
+				part = escapeSeparator(part, seps.sep)
+			}
+			parts = append(parts, part)
+		}
+		if seps.json {
+			text, err := json.Marshal(parts)
+			return string(text), err
+		}
+		if seps.shell {
+			return strings.Join(parts, " "), nil
+		}
+		return strings.Join(parts, seps.sep), nil
+	case reflect.Map:
+		parts := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			key, err := formatValue(k, seps)
+			if err != nil {
+				return "", err
+			}
+			value, err := formatValue(v.MapIndex(k), innerSeps)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, escapeSeparator(key+seps.kv+value, seps.sep))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, seps.sep), nil
+	case reflect.Struct, reflect.Interface, reflect.Func, reflect.Chan:
+		return "", fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return fmt.Sprint(v.Interface()), nil
+}
+
+// escapeSeparator escapes the backslashes and the instances of sep in
+// value, to be read back by splitEscaped.
+func escapeSeparator(value, sep string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return strings.ReplaceAll(value, sep, `\`+sep)
+}
@@ -0,0 +1,21 @@
+package env
+
+// Must panics with err if it is not nil. It is meant for main functions and
+// examples, where there is nothing better to do with a configuration error:
+//
+//	env.Must(env.Parse(&cfg))
+func Must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// MustParse is like ParseAs, except that it panics with the error instead
+// of returning it.
+//
+//	var cfg = env.MustParse[config](env.WithPrefix("APP_"))
+func MustParse[T any](opts ...Option) T {
+	v, err := ParseAs[T](opts...)
+	Must(err)
+	return v
+}
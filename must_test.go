@@ -0,0 +1,25 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMust(t *testing.T) {
+	assert.NotPanics(t, func() { Must(nil) })
+	assert.PanicsWithError(t, ErrNotAStructPtr.Error(), func() { Must(ErrNotAStructPtr) })
+}
+
+func TestMustParse(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT,required"`
+	}
+	src := MapSource{"APP_PORT": "8080"}
+	cfg := MustParse[config](WithPrefix("APP_"), WithSource(src))
+	assert.Equal(t, 8080, cfg.Port)
+
+	assert.PanicsWithError(t, `env: required environment variable "PORT" is not set`, func() {
+		MustParse[config](WithSource(MapSource{}))
+	})
+}
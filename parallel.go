@@ -0,0 +1,100 @@
+package env
+
+import (
+	"reflect"
+	"sync"
+)
+
+// WithConcurrency makes Parse look up to n variables at once in a
+// ContextSource, such as a remote secret store, instead of one after the
+// other, cutting startup latency for structs with many remote values. Fields
+// are still set in declaration order, and errors are the same as without
+// concurrency.
+func WithConcurrency(n int) Option {
+	return func(o *Options) {
+		o.Concurrency = n
+	}
+}
+
+type prefetched struct {
+	value  string
+	exists bool
+	source Source
+	err    error
+}
+
+// prefetch looks the variables of the structs pointed to by vs up
+// concurrently, for lookupSource to use them.
+func (p *parser) prefetch(vs []interface{}) {
+	cs, ok := p.Source.(ContextSource)
+	if !ok || p.Concurrency <= 1 {
+		return
+	}
+	var keys []string
+	seen := map[string]bool{}
+	for _, v := range vs {
+		ref := reflect.ValueOf(v)
+		if ref.Kind() == reflect.Ptr && ref.Elem().Kind() == reflect.Struct {
+			p.collectKeys(p.Prefix, "", ref.Elem(), seen, &keys)
+		}
+	}
+
+	results := make([]prefetched, len(keys))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < p.Concurrency && w < len(keys); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				r := &results[i]
+				r.value, r.exists, r.source, r.err = p.lookupRetry(cs, keys[i])
+			}
+		}()
+	}
+	for i := range keys {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	p.prefetched = make(map[string]prefetched, len(keys))
+	for i, key := range keys {
+		p.prefetched[key] = results[i]
+	}
+}
+
+// collectKeys appends to keys the variables doParse would look up in ref,
+// except the ones of drivers, which depend on values, and of Lazy fields,
+// which are looked up when needed.
+func (p *parser) collectKeys(prefix, path string, ref reflect.Value, seen map[string]bool, keys *[]string) {
+	refType := ref.Type()
+	for i := 0; i < refType.NumField(); i++ {
+		sf := refType.Field(i)
+		field := ref.Field(i)
+		if sf.PkgPath != "" && !p.Unexported {
+			continue
+		}
+		envPrefix := sf.Tag.Get("envPrefix")
+		if field.Kind() == reflect.Ptr && !field.IsNil() {
+			if field.Elem().Kind() == reflect.Struct {
+				p.collectKeys(prefix+envPrefix, path+sf.Name+".", field.Elem(), seen, keys)
+			}
+			continue
+		}
+		if field.Kind() == reflect.Struct && sf.Type.Name() == "" {
+			p.collectKeys(prefix+envPrefix, path+sf.Name+".", field, seen, keys)
+			continue
+		}
+		if field.CanAddr() && asLazy(field) != nil {
+			continue
+		}
+		if params, err := p.fieldParams(prefix, path+sf.Name, sf); err == nil && params.Key != "" && !seen[params.Key] {
+			seen[params.Key] = true
+			*keys = append(*keys, params.Key)
+		}
+		if field.Kind() == reflect.Struct && asTracker(field) == nil {
+			p.collectKeys(prefix+envPrefix, path+sf.Name+".", field, seen, keys)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type slowSource struct {
+	mu       sync.Mutex
+	running  int
+	max      int
+	lookups  map[string]int
+	failures map[string]error
+}
+
+func (s *slowSource) Lookup(key string) (string, bool) {
+	v, ok, _ := s.LookupContext(context.Background(), key)
+	return v, ok
+}
+
+func (s *slowSource) LookupContext(_ context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	s.running++
+	if s.running > s.max {
+		s.max = s.running
+	}
+	s.lookups[key]++
+	err := s.failures[key]
+	s.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	s.mu.Lock()
+	s.running--
+	s.mu.Unlock()
+	if key == "APP_UNSET" || err != nil {
+		return "", false, err
+	}
+	return "value-" + key, true, nil
+}
+
+func TestConcurrency(t *testing.T) {
+	type inner struct {
+		D string `env:"D"`
+	}
+	type config struct {
+		A     string `env:"A"`
+		B     string `env:"B"`
+		C     string `env:"C"`
+		Unset string `env:"UNSET" envDefault:"default"`
+		Inner inner  `envPrefix:"INNER_"`
+		Ptr   *inner `envPrefix:"PTR_"`
+		Nil   *inner `envPrefix:"NIL_"`
+		Anon  struct {
+			E string `env:"E"`
+		} `envPrefix:"ANON_"`
+		Tracked Tracked[string] `env:"F"`
+		URL     string          `env:"URL" envDefault:"${A}/${B}" envExpand:"true"`
+	}
+	src := &slowSource{lookups: map[string]int{}}
+	cfg := config{Ptr: &inner{}}
+	require.NoError(t, Parse(&cfg, WithSource(src), WithPrefix("APP_"), WithConcurrency(4)))
+
+	assert.Equal(t, "value-APP_A", cfg.A)
+	assert.Equal(t, "default", cfg.Unset)
+	assert.Equal(t, "value-APP_INNER_D", cfg.Inner.D)
+	assert.Equal(t, "value-APP_PTR_D", cfg.Ptr.D)
+	assert.Nil(t, cfg.Nil)
+	assert.Equal(t, "value-APP_ANON_E", cfg.Anon.E)
+	assert.Equal(t, "value-APP_F", cfg.Tracked.Value())
+	assert.Equal(t, "value-APP_URL", cfg.URL)
+	assert.Equal(t, 4, src.max)
+	for key, n := range src.lookups {
+		assert.Equal(t, 1, n, key)
+	}
+	assert.Len(t, src.lookups, 9)
+}
+
+func TestConcurrencyErrors(t *testing.T) {
+	type config struct {
+		A string `env:"A"`
+		B string `env:"B"`
+		C string `env:"C"`
+	}
+	src := &slowSource{lookups: map[string]int{}, failures: map[string]error{
+		"B": errors.New("b failed"),
+		"C": errors.New("c failed"),
+	}}
+	var cfg config
+	err := Parse(&cfg, WithSource(src), WithConcurrency(3))
+	assert.EqualError(t, err, `env: could not look up "B": b failed`)
+	assert.Equal(t, 3, src.max)
+}
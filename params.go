@@ -0,0 +1,181 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FieldParams describes a field as configured by its tags.
+type FieldParams struct {
+	// Field is the path of the field, e.g. "Database.URL".
+	Field string
+
+	// OwnKey is the key in the `env` tag, and Key the full name of the
+	// variable, prefix included.
+	OwnKey string
+	Key    string
+
+	// DefaultValue is the `envDefault` tag, HasDefaultValue whether it is set.
+	DefaultValue    string
+	HasDefaultValue bool
+
+	// Required, LoadFile, Sensitive, NoPrefix and Relative are set by the
+	// `required`, `file`, `sensitive`, `noprefix` and `relative` tag options.
+	// Sensitive is also set for fields of types implementing Sensitive.
+	Required  bool
+	LoadFile  bool
+	Sensitive bool
+	NoPrefix  bool
+	Relative  bool
+
+	// Chunked is set by the `chunked` tag option, for values split across
+	// several variables.
+	Chunked bool
+
+	// Trim is set by the `trim` tag option, or for every field by
+	// WithTrimSpace.
+	Trim bool
+
+	// Unquote is set by the `unquote` tag option, or for every field by
+	// WithUnquote.
+	Unquote bool
+
+	// Multiline is set by the `multiline` tag option, for values with
+	// escaped line breaks.
+	Multiline bool
+
+	// Base64 is set by the `base64` tag option, for values to decode before
+	// parsing them.
+	Base64 bool
+
+	// Encodings lists the encodings of the `envEncoding` tag, e.g. "gzip"
+	// and "base64" for `envEncoding:"gzip,base64"`, in the order in which
+	// they were applied to the values, followed by base64 for the `base64`
+	// tag option.
+	Encodings []string
+
+	// Expand is set by the `envExpand` tag.
+	Expand bool
+
+	// RestartRequired is set by the `envReload:"restart"` tag, for fields
+	// whose changes cannot be applied while the program runs, as opposed to
+	// `envReload:"hot"`, the default.
+	RestartRequired bool
+
+	// Groups lists the groups of the `envGroup` tag, e.g. "auth" for
+	// `envGroup:"auth"`, which constraints such as WithRequireOneOf refer
+	// to.
+	Groups []string
+
+	// RequiredIfKey and RequiredIfValue are set by the `envRequiredIf` tag,
+	// e.g. "APP_TLS_ENABLED" and "true" for `envRequiredIf:"TLS_ENABLED=true"`
+	// with the prefix APP_. RequiredIfValue is empty if the tag has no value,
+	// for fields required whenever the other variable is set.
+	RequiredIfKey   string
+	RequiredIfValue string
+}
+
+func newFieldParams(prefix, path string, sf reflect.StructField) (FieldParams, error) {
+	key, opts := parseKeyForOption(sf.Tag.Get("env"))
+	params := FieldParams{
+		Field:  path,
+		OwnKey: key,
+		Expand: strings.EqualFold(sf.Tag.Get("envExpand"), "true"),
+	}
+	if key != "" {
+		params.Key = prefix + key
+	}
+	params.DefaultValue, params.HasDefaultValue = sf.Tag.Lookup("envDefault")
+	params.Sensitive = isSensitive(sf.Type)
+	if cond := sf.Tag.Get("envRequiredIf"); cond != "" {
+		key, value, _ := strings.Cut(cond, "=")
+		params.RequiredIfKey, params.RequiredIfValue = prefix+key, value
+	}
+	if groups := sf.Tag.Get("envGroup"); groups != "" {
+		if reflect.PtrTo(sf.Type).Implements(lazierType) {
+			return FieldParams{}, fmt.Errorf("env: envGroup not supported on Lazy field %q", path)
+		}
+		params.Groups = strings.Split(groups, ",")
+	}
+	switch reload := sf.Tag.Get("envReload"); reload {
+	case "", "hot":
+	case "restart":
+		params.RestartRequired = true
+	default:
+		return FieldParams{}, fmt.Errorf("env: envReload %q not supported, expected hot or restart", reload)
+	}
+
+	if encodings := sf.Tag.Get("envEncoding"); encodings != "" {
+		for _, enc := range strings.Split(encodings, ",") {
+			if _, ok := codecs[enc]; !ok {
+				return FieldParams{}, fmt.Errorf("env: envEncoding %q not supported", enc)
+			}
+			params.Encodings = append(params.Encodings, enc)
+		}
+	}
+
+	for _, opt := range opts {
+		switch opt {
+		case "":
+			break
+		case "file":
+			params.LoadFile = true
+		case "required":
+			params.Required = true
+		case "sensitive":
+			params.Sensitive = true
+		case "noprefix":
+			params.NoPrefix = true
+		case "relative":
+			params.Relative = true
+		case "chunked":
+			params.Chunked = true
+		case "trim":
+			params.Trim = true
+		case "unquote":
+			params.Unquote = true
+		case "multiline":
+			params.Multiline = true
+		case "base64":
+			params.Base64 = true
+		case "jsonArray", "shellWords", "reread":
+			break
+		default:
+			return FieldParams{}, fmt.Errorf("env: tag option %q not supported", opt)
+		}
+	}
+	if params.Base64 {
+		params.Encodings = append(params.Encodings, "base64")
+	}
+	if n := len(params.Encodings); n > 0 && params.Encodings[n-1] != "base64" {
+		return FieldParams{}, fmt.Errorf("env: envEncoding %q must end with base64, as variables cannot hold binary values", sf.Tag.Get("envEncoding"))
+	}
+	return params, nil
+}
+
+// fieldParams is like newFieldParams, except that the keys of fields tagged
+// with `noprefix` or listed in PrefixExceptions do not start with Prefix.
+func (p *parser) fieldParams(prefix, path string, sf reflect.StructField) (FieldParams, error) {
+	params, err := newFieldParams(prefix, path, sf)
+	if err != nil {
+		return params, err
+	}
+	params.Trim = params.Trim || p.TrimSpace
+	params.Unquote = params.Unquote || p.Unquote
+	if params.Key == "" {
+		return params, nil
+	}
+	key := strings.TrimPrefix(params.Key, p.Prefix)
+	if params.NoPrefix {
+		params.Key = key
+		return params, nil
+	}
+	for _, exception := range p.PrefixExceptions {
+		if key == exception {
+			params.Key = key
+			break
+		}
+	}
+	return params, nil
+}
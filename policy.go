@@ -0,0 +1,56 @@
+package env
+
+import (
+	"fmt"
+	"path"
+)
+
+// Policy constrains the values of the variables whose names match Pattern,
+// whatever the fields reading them, so that conventions such as "every URL
+// uses https" are enforced in one place.
+type Policy struct {
+	// Pattern is matched against the full names of the variables, prefix
+	// included, with path.Match, e.g. "*_URL".
+	Pattern string
+
+	// Check returns an error if value is not allowed for the variable key.
+	Check func(key, value string) error
+}
+
+// WithPolicy adds a Policy: Parse fails if check returns an error for the
+// value of a variable whose name matches pattern, e.g.
+//
+//	env.WithPolicy("*_URL", func(key, value string) error {
+//		if u, err := url.Parse(value); err != nil || u.Scheme != "https" {
+//			return errors.New("must be an https URL")
+//		}
+//		return nil
+//	})
+//
+// Policies apply to the values of the variables and the defaults of their
+// fields, as well as the contents of the files loaded with the `file`
+// option, but not to the variables that are not set.
+func WithPolicy(pattern string, check func(key, value string) error) Option {
+	return func(o *Options) {
+		o.Policies = append(o.Policies, Policy{Pattern: pattern, Check: check})
+	}
+}
+
+// checkPolicies returns an error if val, the value of the field described
+// by params, is not allowed by a Policy. The value is left out of the error,
+// as it may be sensitive.
+func (p *parser) checkPolicies(params FieldParams, val string) error {
+	for _, policy := range p.Policies {
+		ok, err := path.Match(policy.Pattern, params.Key)
+		if err != nil {
+			return fmt.Errorf("env: invalid policy pattern %q: %w", policy.Pattern, err)
+		}
+		if !ok {
+			continue
+		}
+		if err := policy.Check(params.Key, val); err != nil {
+			return fmt.Errorf("env: value of environment variable %q violates policy %q: %w", params.Key, policy.Pattern, err)
+		}
+	}
+	return nil
+}
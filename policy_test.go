@@ -0,0 +1,38 @@
+package env
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy(t *testing.T) {
+	type config struct {
+		APIURL     string   `env:"API_URL"`
+		WebhookURL *url.URL `env:"WEBHOOK_URL" envDefault:"http://localhost"`
+		Name       string   `env:"NAME"`
+	}
+	https := WithPolicy("*_URL", func(key, value string) error {
+		if u, err := url.Parse(value); err != nil || u.Scheme != "https" {
+			return errors.New("must be an https URL")
+		}
+		return nil
+	})
+
+	var cfg config
+	err := Parse(&cfg, WithSource(MapSource{"APP_API_URL": "https://api", "APP_WEBHOOK_URL": "https://hook"}), WithPrefix("APP_"), https)
+	require.NoError(t, err)
+	assert.Equal(t, "https://api", cfg.APIURL)
+
+	err = Parse(&config{}, WithSource(MapSource{"API_URL": "http://api", "WEBHOOK_URL": "https://hook"}), https)
+	assert.EqualError(t, err, `env: value of environment variable "API_URL" violates policy "*_URL": must be an https URL`)
+
+	err = Parse(&config{}, WithSource(MapSource{}), https)
+	assert.EqualError(t, err, `env: value of environment variable "WEBHOOK_URL" violates policy "*_URL": must be an https URL`)
+
+	err = Parse(&config{}, WithSource(MapSource{"NAME": "x"}), WithPolicy("[", func(key, value string) error { return nil }))
+	assert.EqualError(t, err, `env: invalid policy pattern "[": syntax error in pattern`)
+}
@@ -0,0 +1,127 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// provenance describes where the value of a field came from.
+type provenance struct {
+	origin Origin
+	source Source
+	file   string
+	refs   []string
+}
+
+func (p provenance) sourceName() string {
+	if p.source == nil {
+		return ""
+	}
+	return SourceName(p.source)
+}
+
+// SourceName returns the name of s used in reports: the result of its
+// String method if it has one, e.g. "os" for OSSource, or its type otherwise.
+func SourceName(s Source) string {
+	if str, ok := s.(fmt.Stringer); ok {
+		return str.String()
+	}
+	return fmt.Sprintf("%T", s)
+}
+
+// String implements fmt.Stringer.
+func (OSSource) String() string {
+	return "os"
+}
+
+// String implements fmt.Stringer.
+func (MapSource) String() string {
+	return "map"
+}
+
+// String implements fmt.Stringer.
+func (d dirSource) String() string {
+	return "dir " + string(d)
+}
+
+// String implements fmt.Stringer.
+func (c *SourceCache) String() string {
+	return "cached " + SourceName(c.source)
+}
+
+// sourceRecorder records the member of a ChainSource that supplied a value.
+type sourceRecorder struct {
+	mu sync.Mutex
+	s  Source
+}
+
+// source returns the recorded Source, or def if none was recorded.
+func (r *sourceRecorder) source(def Source) Source {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.s == nil {
+		return def
+	}
+	return r.s
+}
+
+type sourceRecorderKey struct{}
+
+func withSourceRecorder(ctx context.Context, r *sourceRecorder) context.Context {
+	return context.WithValue(ctx, sourceRecorderKey{}, r)
+}
+
+// recordSource records s as the Source that supplied the value looked up
+// with ctx, unless a nested ChainSource already recorded one of its members.
+func recordSource(ctx context.Context, s Source) {
+	r, ok := ctx.Value(sourceRecorderKey{}).(*sourceRecorder)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.s == nil {
+		r.s = s
+	}
+}
+
+// Provenance maps the path of each field to a description of where its
+// value came from, e.g. "APP_TIMEOUT from os" or "default of APP_TIMEOUT",
+// followed by the file it was read from and the variables it refers to, if
+// any. It answers "why is this value 30s?" from a single log line:
+//
+//	log.Printf("config: %v", report.Provenance())
+func (r Report) Provenance() map[string]string {
+	m := make(map[string]string, len(r.Fields))
+	for _, f := range r.Fields {
+		m[f.Field] = f.provenance()
+	}
+	return m
+}
+
+func (f FieldReport) provenance() string {
+	var b strings.Builder
+	switch f.Origin {
+	case OriginEnv:
+		b.WriteString(f.Key)
+		if f.Source != "" {
+			b.WriteString(" from " + f.Source)
+		}
+	case OriginDefault:
+		b.WriteString("default of " + f.Key)
+	default:
+		b.WriteString(f.Key + " not set")
+	}
+	if f.File != "" {
+		b.WriteString(", file " + f.File)
+	}
+	if len(f.Refs) > 0 {
+		refs := append([]string{}, f.Refs...)
+		sort.Strings(refs)
+		b.WriteString(", expanding " + strings.Join(refs, " "))
+	}
+	return b.String()
+}
@@ -0,0 +1,72 @@
+package env
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvenance(t *testing.T) {
+	dir := t.TempDir()
+	cert := filepath.Join(dir, "cert.pem")
+	require.NoError(t, os.WriteFile(cert, []byte("-----BEGIN CERTIFICATE-----"), 0o600))
+	secrets := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(secrets, "PASSWORD"), []byte("hunter2"), 0o600))
+
+	type config struct {
+		Host     string `env:"HOST"`
+		Port     int    `env:"PORT" envDefault:"8080"`
+		Password string `env:"PASSWORD,sensitive"`
+		Cert     string `env:"CERT,file"`
+		URL      string `env:"URL" envExpand:"true" envDefault:"http://${HOST}:${PORT}"`
+		Debug    bool   `env:"DEBUG"`
+	}
+	source := ChainSource(
+		DirSource(secrets),
+		MapSource{"HOST": "localhost", "CERT": cert},
+	)
+
+	for name, opts := range map[string][]Option{
+		"sequential": nil,
+		"concurrent": {WithConcurrency(4)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var cfg config
+			var r Report
+			require.NoError(t, Parse(&cfg, append(opts, WithSource(source), WithReport(&r))...))
+			assert.Equal(t, map[string]string{
+				"Host":     "HOST from map",
+				"Port":     "default of PORT",
+				"Password": "PASSWORD from dir " + secrets,
+				"Cert":     "CERT from map, file " + cert,
+				"URL":      "default of URL, expanding HOST PORT",
+				"Debug":    "DEBUG not set",
+			}, r.Provenance())
+			for _, f := range r.Fields {
+				if f.Field == "URL" {
+					assert.Equal(t, []string{"HOST", "PORT"}, f.Refs)
+				}
+			}
+		})
+	}
+}
+
+func TestSourceName(t *testing.T) {
+	assert.Equal(t, "os", SourceName(OSSource{}))
+	assert.Equal(t, "cached map", SourceName(CachedSource(MapSource{}, 0)))
+	assert.Equal(t, "env.SourceFunc", SourceName(SourceFunc(func(string) (string, bool) { return "", false })))
+}
+
+func TestRecordSourceNested(t *testing.T) {
+	inner := MapSource{"A": "a"}
+	source := ChainSource(MapSource{}, ChainSource(SourceFunc(func(string) (string, bool) { return "", false }), inner))
+	rec := &sourceRecorder{}
+	_, ok, err := lookupContext(withSourceRecorder(context.Background(), rec), source, "A")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, inner, rec.source(nil))
+}
@@ -0,0 +1,45 @@
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseFromReader is like Parse, except that the variables are read from r
+// instead of the process environment, as newline-delimited KEY=VALUE pairs
+// such as the output of `env`, a mounted config file or a pipe. Blank lines
+// and lines starting with # are ignored, and values are taken as is: use the
+// dotenv package for files with quoted values.
+//
+// In strict mode, the variables of r are the ones checked for being used.
+func ParseFromReader(r io.Reader, v interface{}, opts ...Option) error {
+	vars, err := readVars(r)
+	if err != nil {
+		return err
+	}
+	return Parse(v, append([]Option{WithSource(vars)}, opts...)...)
+}
+
+func readVars(r io.Reader) (MapSource, error) {
+	vars := MapSource{}
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("env: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line != "" && !strings.HasPrefix(line, "#") {
+			key, value, ok := strings.Cut(line, "=")
+			if !ok || key == "" {
+				return nil, fmt.Errorf(`env: line %d: expected KEY=VALUE`, n)
+			}
+			vars[key] = value
+		}
+		if err == io.EOF {
+			return vars, nil
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package env
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFromReader(t *testing.T) {
+	type config struct {
+		Port  int      `env:"APP_PORT"`
+		Hosts []string `env:"APP_HOSTS"`
+		Query string   `env:"APP_QUERY"`
+		Debug bool     `env:"APP_DEBUG" envDefault:"true"`
+	}
+	input := "# generated\r\nAPP_PORT=8080\r\n\r\nAPP_HOSTS=a,b\nAPP_QUERY=a=b\nOTHER=ignored"
+
+	var cfg config
+	require.NoError(t, ParseFromReader(strings.NewReader(input), &cfg))
+	assert.Equal(t, config{Port: 8080, Hosts: []string{"a", "b"}, Query: "a=b", Debug: true}, cfg)
+}
+
+func TestParseFromReaderStrict(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT"`
+	}
+	var cfg config
+	err := ParseFromReader(strings.NewReader("APP_PORT=1\nAPP_PROT=2\n"), &cfg, WithPrefix("APP_"), WithStrict())
+	assert.EqualError(t, err, `env: unknown environment variables with prefix "APP_": APP_PROT`)
+}
+
+func TestParseFromReaderInvalidLine(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT"`
+	}
+	var cfg config
+	err := ParseFromReader(strings.NewReader("PORT=1\nsecret\n"), &cfg)
+	assert.EqualError(t, err, `env: line 2: expected KEY=VALUE`)
+}
@@ -0,0 +1,84 @@
+package env
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Parser parses configurations with the same options, and remembers the
+// values it parsed, so that Refresh only parses the fields whose variables
+// changed. This keeps reloads cheap for large structs using expensive custom
+// parsers.
+type Parser struct {
+	opts []Option
+
+	mu    sync.Mutex
+	cache map[string]parsedValue
+}
+
+// parsedValue is the value parsed for a field, and the string it was parsed
+// from.
+type parsedValue struct {
+	raw   string
+	value reflect.Value
+}
+
+// NewParser returns a Parser using the given options.
+func NewParser(opts ...Option) *Parser {
+	return &Parser{opts: opts}
+}
+
+// Parse is like the Parse function, and remembers the values it parsed.
+func (r *Parser) Parse(v interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := newParser(r.opts)
+	p.parsed = map[string]parsedValue{}
+	if err := p.parse(v); err != nil {
+		return err
+	}
+	r.cache = p.parsed
+	return nil
+}
+
+// Refresh looks the variables of v, as populated by Parse, up again, and
+// only parses the ones whose value changed since the last call to Parse or
+// Refresh, reusing the previous values of the other fields. As with Watch,
+// variables that were unset get their default or zero value again, and
+// untagged fields are kept. Fields with the relative option are always
+// parsed again, as their value also depends on the clock. It returns the
+// fields that changed.
+//
+// v is only modified if Refresh succeeds: if a variable was set to an
+// invalid value, v keeps the last good configuration, and the next Refresh
+// compares the environment with it again.
+func (r *Parser) Refresh(v interface{}) ([]Change, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ref := reflect.ValueOf(v)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Struct {
+		return nil, ErrNotAStructPtr
+	}
+	next := reflect.ValueOf(Clone(v))
+	p := newParser(r.opts)
+	p.reset(next.Elem())
+	p.cache = r.cache
+	p.parsed = map[string]parsedValue{}
+	if err := p.parse(next.Interface()); err != nil {
+		return nil, err
+	}
+	changes, err := Diff(v, next.Interface(), r.opts...)
+	if err != nil {
+		return nil, err
+	}
+	ref.Elem().Set(next.Elem())
+	r.cache = p.parsed
+	return changes, nil
+}
+
+// remember records the value parsed for the field at path.
+func (p *parser) remember(path, raw string, field reflect.Value) {
+	value := reflect.New(field.Type()).Elem()
+	value.Set(copyValue(field))
+	p.parsed[path] = parsedValue{raw: raw, value: value}
+}
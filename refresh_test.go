@@ -0,0 +1,100 @@
+package env
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type expensive struct {
+	Value string
+}
+
+func TestParserRefresh(t *testing.T) {
+	type config struct {
+		A     expensive  `env:"A"`
+		B     expensive  `env:"B"`
+		Hosts []string   `env:"HOSTS"`
+		Port  int        `env:"PORT" envDefault:"80"`
+		Opt   *expensive `env:"OPT"`
+		Other string
+	}
+	var parsed []string
+	funcs := WithFuncs(map[reflect.Type]ParserFunc{
+		reflect.TypeOf(expensive{}): func(v string) (interface{}, error) {
+			parsed = append(parsed, v)
+			return expensive{Value: strings.ToUpper(v)}, nil
+		},
+	})
+	src := &mutableSource{vars: map[string]string{"A": "a", "B": "b", "HOSTS": "x,y", "PORT": "8080"}}
+	parser := NewParser(WithSource(src), funcs)
+
+	cfg := config{Other: "kept"}
+	require.NoError(t, parser.Parse(&cfg))
+	assert.Equal(t, []string{"a", "b"}, parsed)
+
+	parsed = nil
+	changes, err := parser.Refresh(&cfg)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Empty(t, parsed)
+	assert.Equal(t, config{A: expensive{"A"}, B: expensive{"B"}, Hosts: []string{"x", "y"}, Port: 8080, Other: "kept"}, cfg)
+
+	src.set("B", "c")
+	src.set("OPT", "o")
+	src.unset("PORT")
+	changes, err = parser.Refresh(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "o"}, parsed)
+	assert.Len(t, changes, 3)
+	assert.Equal(t, config{A: expensive{"A"}, B: expensive{"C"}, Hosts: []string{"x", "y"}, Port: 80, Opt: &expensive{"O"}, Other: "kept"}, cfg)
+
+	// the parsed values are not shared with the configuration
+	cfg.Hosts[0] = "changed"
+	_, err = parser.Refresh(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x", "y"}, cfg.Hosts)
+}
+
+func TestParserRefreshError(t *testing.T) {
+	type config struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+	}
+	src := &mutableSource{vars: map[string]string{"HOST": "a", "PORT": "8080"}}
+	parser := NewParser(WithSource(src))
+	var cfg config
+	require.NoError(t, parser.Parse(&cfg))
+
+	src.set("HOST", "b")
+	src.set("PORT", "http")
+	_, err := parser.Refresh(&cfg)
+	assert.Error(t, err)
+	assert.Equal(t, config{Host: "a", Port: 8080}, cfg)
+
+	_, err = parser.Refresh(cfg)
+	assert.Equal(t, ErrNotAStructPtr, err)
+}
+
+func TestParserRefreshRelative(t *testing.T) {
+	type config struct {
+		Deadline time.Time `env:"DEADLINE,relative"`
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	src := &mutableSource{vars: map[string]string{"DEADLINE": "now+1h"}}
+	parser := NewParser(WithSource(src), WithClock(func() time.Time { return now }))
+
+	var cfg config
+	require.NoError(t, parser.Parse(&cfg))
+	assert.Equal(t, now.Add(time.Hour), cfg.Deadline)
+
+	now = now.Add(24 * time.Hour)
+	changes, err := parser.Refresh(&cfg)
+	require.NoError(t, err)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, now.Add(time.Hour), cfg.Deadline)
+}
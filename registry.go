@@ -0,0 +1,105 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// nolint: gochecknoglobals
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]interface{}{}
+)
+
+// Register registers a configuration struct under name, typically from the
+// init function of the package it configures, and returns the pointer that
+// LoadAll fills:
+//
+//	var cfg = env.Register[config]("http")
+//
+// Register panics if T is not a struct or if name is already registered.
+func Register[T any](name string) *T {
+	if t := reflect.TypeOf((*T)(nil)).Elem(); t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("env: Register called with non-struct type %s", t))
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[name]; dup {
+		panic(fmt.Sprintf("env: Register called twice for %q", name))
+	}
+	cfg := new(T)
+	registry[name] = cfg
+	return cfg
+}
+
+// Registration is a configuration struct registered with Register.
+type Registration struct {
+	Name string
+
+	// Value is the pointer to the struct returned by Register.
+	Value interface{}
+}
+
+// Registrations returns the registered configuration structs, sorted by
+// name, e.g. to generate the documentation of the whole program.
+func Registrations() []Registration {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	regs := make([]Registration, 0, len(registry))
+	for name, v := range registry {
+		regs = append(regs, Registration{Name: name, Value: v})
+	}
+	sort.Slice(regs, func(i, j int) bool {
+		return regs[i].Name < regs[j].Name
+	})
+	return regs
+}
+
+// LoadAll parses every registered configuration struct with the given
+// options, as ParseAll does. It fails without parsing anything if several
+// structs read the same variable, which usually means that two packages
+// unknowingly compete for it.
+//
+// LoadAll is meant to be called once, from main, before the registered
+// structs are used.
+func LoadAll(opts ...Option) error {
+	regs := Registrations()
+	if err := checkCollisions(regs); err != nil {
+		return err
+	}
+	values := make([]interface{}, 0, len(regs))
+	for _, reg := range regs {
+		values = append(values, reg.Value)
+	}
+	return ParseAll(values, opts...)
+}
+
+func checkCollisions(regs []Registration) error {
+	owners := map[string][]string{}
+	var keys []string
+	for _, reg := range regs {
+		seen := map[string]bool{}
+		for _, key := range typeKeys(reflect.TypeOf(reg.Value).Elem(), "") {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if len(owners[key]) == 1 {
+				keys = append(keys, key)
+			}
+			owners[key] = append(owners[key], reg.Name)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+	msgs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		msgs = append(msgs, fmt.Sprintf("%s (%s)", key, strings.Join(owners[key], ", ")))
+	}
+	return fmt.Errorf("env: variables read by several registered configs: %s", strings.Join(msgs, "; "))
+}
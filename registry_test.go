@@ -0,0 +1,81 @@
+package env
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withRegistry(t *testing.T) {
+	t.Helper()
+	registryMu.Lock()
+	saved := registry
+	registry = map[string]interface{}{}
+	registryMu.Unlock()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry = saved
+		registryMu.Unlock()
+	})
+}
+
+func TestRegister(t *testing.T) {
+	withRegistry(t)
+	type httpConfig struct {
+		Port int `env:"HTTP_PORT" envDefault:"8080"`
+	}
+	type dbConfig struct {
+		URL string `env:"DATABASE_URL,required"`
+	}
+	http := Register[httpConfig]("http")
+	db := Register[dbConfig]("db")
+
+	defer os.Clearenv()
+	os.Setenv("DATABASE_URL", "postgres://")
+	require.NoError(t, LoadAll())
+	assert.Equal(t, 8080, http.Port)
+	assert.Equal(t, "postgres://", db.URL)
+
+	regs := Registrations()
+	require.Len(t, regs, 2)
+	assert.Equal(t, "db", regs[0].Name)
+	assert.Equal(t, db, regs[0].Value)
+
+	os.Setenv("APP_DATABASE_URL", "postgres://")
+	os.Setenv("APP_HTTP_PORT", "1")
+	os.Setenv("APP_HTTP_PROT", "1")
+	err := LoadAll(WithPrefix("APP_"), WithStrict())
+	assert.EqualError(t, err, `env: unknown environment variables with prefix "APP_": APP_HTTP_PROT`)
+}
+
+func TestRegisterCollisions(t *testing.T) {
+	withRegistry(t)
+	type a struct {
+		Port  int    `env:"PORT"`
+		Level string `env:"LOG_LEVEL"`
+	}
+	type b struct {
+		Inner struct {
+			Port int `env:"PORT"`
+		}
+		Level string `env:"LOG_LEVEL"`
+	}
+	Register[a]("a")
+	Register[b]("b")
+	Register[b]("c")
+	assert.EqualError(t, LoadAll(), "env: variables read by several registered configs: LOG_LEVEL (a, b, c); PORT (a, b, c)")
+}
+
+func TestRegisterPanics(t *testing.T) {
+	withRegistry(t)
+	type config struct{}
+	Register[config]("a")
+	assert.PanicsWithValue(t, `env: Register called twice for "a"`, func() {
+		Register[config]("a")
+	})
+	assert.PanicsWithValue(t, "env: Register called with non-struct type string", func() {
+		Register[string]("b")
+	})
+}
@@ -0,0 +1,65 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// WithClock sets the function returning the current time, used by time-based
+// features such as the `relative` tag option, e.g. to make tests
+// deterministic.
+func WithClock(clock func() time.Time) Option {
+	return func(o *Options) {
+		o.Clock = clock
+	}
+}
+
+func (p *parser) now() time.Time {
+	if p.Clock != nil {
+		return p.Clock()
+	}
+	return time.Now()
+}
+
+// setRelative sets a time.Time field with the `relative` tag option, whose
+// value is either "now", a signed duration from now such as "+24h", "now+15m"
+// or "now-1h30m", or an RFC 3339 time.
+func (p *parser) setRelative(field reflect.Value, sf reflect.StructField, value string) error {
+	typee := sf.Type
+	if typee.Kind() == reflect.Ptr {
+		typee = typee.Elem()
+	}
+	if typee != reflect.TypeOf(time.Time{}) {
+		return fmt.Errorf(`env: relative option is not supported on field "%s" of type "%s"`, sf.Name, sf.Type)
+	}
+	t, err := parseRelative(value, p.now())
+	if err != nil {
+		return newParseError(sf, err)
+	}
+	if sf.Type.Kind() == reflect.Ptr {
+		field.Set(reflect.ValueOf(&t))
+		return nil
+	}
+	field.Set(reflect.ValueOf(t))
+	return nil
+}
+
+func parseRelative(value string, now time.Time) (time.Time, error) {
+	rest := strings.TrimPrefix(value, "now")
+	if rest == value && !strings.HasPrefix(value, "+") && !strings.HasPrefix(value, "-") {
+		return time.Parse(time.RFC3339, value)
+	}
+	if rest == "" {
+		return now, nil
+	}
+	if rest[0] != '+' && rest[0] != '-' {
+		return time.Time{}, fmt.Errorf("invalid relative time %q", value)
+	}
+	d, err := time.ParseDuration(rest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid relative time %q: %w", value, err)
+	}
+	return now.Add(d), nil
+}
@@ -0,0 +1,65 @@
+package env
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelative(t *testing.T) {
+	type config struct {
+		Now      time.Time          `env:"NOW,relative"`
+		Expiry   time.Time          `env:"EXPIRY,relative" envDefault:"now+15m"`
+		Start    *time.Time         `env:"START,relative"`
+		Absolute time.Time          `env:"ABSOLUTE,relative"`
+		Tracked  Tracked[time.Time] `env:"TRACKED,relative"`
+		Token    time.Time          `env:"TOKEN_EXPIRY,relative"`
+		Past     time.Time          `env:"PAST,relative"`
+	}
+	defer os.Clearenv()
+	os.Setenv("NOW", "now")
+	os.Setenv("START", "now-1h30m")
+	os.Setenv("ABSOLUTE", "2020-01-02T03:04:05Z")
+	os.Setenv("TRACKED", "now+1s")
+	os.Setenv("TOKEN_EXPIRY", "+24h")
+	os.Setenv("PAST", "-30m")
+
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithClock(func() time.Time { return now })))
+	assert.Equal(t, now, cfg.Now)
+	assert.Equal(t, now.Add(15*time.Minute), cfg.Expiry)
+	assert.Equal(t, now.Add(-90*time.Minute), *cfg.Start)
+	assert.Equal(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), cfg.Absolute)
+	assert.Equal(t, now.Add(time.Second), cfg.Tracked.Value())
+	assert.Equal(t, "now+1s", cfg.Tracked.Raw())
+	assert.Equal(t, now.Add(24*time.Hour), cfg.Token)
+	assert.Equal(t, now.Add(-30*time.Minute), cfg.Past)
+}
+
+func TestRelativeErrors(t *testing.T) {
+	defer os.Clearenv()
+
+	type config struct {
+		Expiry time.Time `env:"EXPIRY,relative"`
+	}
+	for value, msg := range map[string]string{
+		"now*2":    `env: parse error on field "Expiry" of type "time.Time" (EXPIRY="now*2"): invalid relative time "now*2"`,
+		"now+soon": `env: parse error on field "Expiry" of type "time.Time" (EXPIRY="now+soon"): invalid relative time "now+soon": time: invalid duration "+soon"`,
+		"+1 day":   `env: parse error on field "Expiry" of type "time.Time" (EXPIRY="+1 day"): invalid relative time "+1 day": time: unknown unit " day" in duration "+1 day"`,
+	} {
+		os.Setenv("EXPIRY", value)
+		var cfg config
+		assert.EqualError(t, Parse(&cfg), msg)
+	}
+
+	type badConfig struct {
+		Timeout time.Duration `env:"TIMEOUT,relative"`
+	}
+	os.Setenv("TIMEOUT", "now")
+	var cfg badConfig
+	assert.EqualError(t, Parse(&cfg), `env: relative option is not supported on field "Timeout" of type "time.Duration"`)
+}
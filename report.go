@@ -0,0 +1,250 @@
+package env
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redacted replaces the values of sensitive fields.
+const redacted = "*****"
+
+// Report describes how a Parse call resolved each field.
+type Report struct {
+	// Fields lists the fields looked up, in the order they were resolved.
+	Fields []FieldReport
+
+	// Duration is the time Parse took.
+	Duration time.Duration
+
+	// Err is the error returned by Parse, if any.
+	Err error
+}
+
+// FieldReport describes how a single field was resolved.
+type FieldReport struct {
+	// Field is the path of the field, e.g. "Database.URL".
+	Field string
+
+	// Key is the full name of the variable, prefix included.
+	Key string
+
+	// Origin is where the value came from, empty if the variable was not set
+	// and had no default.
+	Origin Origin
+
+	// Source is the name of the Source that supplied the variable, as
+	// returned by SourceName, if Origin is OriginEnv. For sources built with
+	// ChainSource, it is the member that supplied it.
+	Source string
+
+	// File is the path of the file the value was read from, for fields with
+	// the `file` option.
+	File string
+
+	// Refs lists the variables referred to by the value, in order, for fields
+	// with the `envExpand` tag.
+	Refs []string
+
+	// Value is the string the field was parsed from. It should not be logged
+	// as is if Sensitive is true; see Redacted.
+	Value string
+
+	// Sensitive is true for fields tagged with the `sensitive` option.
+	Sensitive bool
+
+	// Duration is the time it took to look the value up.
+	Duration time.Duration
+
+	// Err is the error that occurred while resolving the field, if any.
+	Err error
+
+	redacted string
+}
+
+// WithReport makes Parse fill r with how each field was resolved.
+func WithReport(r *Report) Option {
+	return func(o *Options) {
+		o.Report = r
+	}
+}
+
+// WithRedactor sets the function masking the values of sensitive fields in
+// reports, errors and the output of Marshal, e.g. to keep the last 4
+// characters visible or to hash them. It is only called for sensitive
+// fields.
+func WithRedactor(redactor func(field FieldParams, value string) string) Option {
+	return func(o *Options) {
+		o.Redactor = redactor
+	}
+}
+
+// Redacted returns Value, masked if the field is sensitive.
+func (f FieldReport) Redacted() string {
+	if f.Sensitive {
+		return f.redacted
+	}
+	return f.Value
+}
+
+// redact masks value if field is sensitive.
+func (p *parser) redact(field FieldParams, value string) string {
+	if !field.Sensitive {
+		return value
+	}
+	if p.Redactor != nil {
+		return p.Redactor(field, value)
+	}
+	if value == "" {
+		return ""
+	}
+	return redacted
+}
+
+// redactedError is an error whose message had a sensitive value masked. It
+// deliberately does not unwrap to the original error, which holds the value.
+type redactedError struct {
+	msg string
+}
+
+func (e redactedError) Error() string {
+	return e.msg
+}
+
+// redactError returns err with the parts of a sensitive value replaced by
+// mask. The input of strconv errors, which is one of parts, is replaced as a
+// whole; in the message of other errors, parts are replaced where they are
+// quoted. If a part still shows up elsewhere in the message, the message is
+// dropped altogether.
+func redactError(err error, parts []string, mask string) error {
+	if ne, ok := err.(*strconv.NumError); ok {
+		masked := *ne
+		masked.Num = mask
+		return &masked
+	}
+	msg, rest := err.Error(), err.Error()
+	for _, part := range parts {
+		if part != "" {
+			msg = strings.ReplaceAll(msg, strconv.Quote(part), strconv.Quote(mask))
+			rest = strings.ReplaceAll(rest, strconv.Quote(part), "")
+		}
+	}
+	for _, part := range parts {
+		if part != "" && strings.Contains(rest, part) {
+			return redactedError{msg: "invalid value"}
+		}
+	}
+	return redactedError{msg: msg}
+}
+
+// sensitiveParts returns value, and the slice elements or map keys and
+// values it holds for the field sf, longest first, so that redactError
+// masks a whole value before any element of it.
+func sensitiveParts(sf reflect.StructField, value string) []string {
+	parts := []string{value}
+	typ := sf.Type
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	sep := tagOr(sf, "envSeparator", ",")
+	inner := tagOr(sf, "envInnerSeparator", "|")
+	switch typ.Kind() {
+	case reflect.Slice:
+		elems, _ := splitElements(sf, value, sep)
+		for _, elem := range elems {
+			parts = append(parts, elem)
+			if typ.Elem().Kind() == reflect.Slice {
+				innerElems, _ := splitElements(sf, elem, inner)
+				parts = append(parts, innerElems...)
+			}
+		}
+	case reflect.Map:
+		kv := tagOr(sf, "envKeyValSeparator", ":")
+		for _, item := range splitEscaped(value, sep) {
+			parts = append(parts, item)
+			pair := strings.SplitN(item, kv, 2)
+			parts = append(parts, pair...)
+			if len(pair) == 2 && typ.Elem().Kind() == reflect.Slice {
+				parts = append(parts, splitEscaped(pair[1], inner)...)
+			}
+		}
+	}
+	sort.SliceStable(parts, func(i, j int) bool {
+		return len(parts[i]) > len(parts[j])
+	})
+	return parts
+}
+
+func (r *Report) add(f FieldReport) {
+	r.Fields = append(r.Fields, f)
+}
+
+// reportError attaches err to the last report entry of the field at path,
+// and returns it.
+func (p *parser) reportError(path string, err error) error {
+	if err == nil || p.Report == nil {
+		return err
+	}
+	for i := len(p.Report.Fields) - 1; i >= 0; i-- {
+		if p.Report.Fields[i].Field == path {
+			p.Report.Fields[i].Err = err
+			break
+		}
+	}
+	return err
+}
+
+type jsonReport struct {
+	DurationNS int64             `json:"duration_ns"`
+	Error      string            `json:"error,omitempty"`
+	Fields     []jsonFieldReport `json:"fields"`
+}
+
+type jsonFieldReport struct {
+	Field      string   `json:"field"`
+	Key        string   `json:"key,omitempty"`
+	Origin     Origin   `json:"origin,omitempty"`
+	Source     string   `json:"source,omitempty"`
+	File       string   `json:"file,omitempty"`
+	Refs       []string `json:"refs,omitempty"`
+	Value      string   `json:"value"`
+	Sensitive  bool     `json:"sensitive,omitempty"`
+	DurationNS int64    `json:"duration_ns"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// MarshalJSON encodes the report as a JSON document suitable for log
+// pipelines and configuration audits. Values of sensitive fields are
+// redacted and durations are given in nanoseconds.
+func (r Report) MarshalJSON() ([]byte, error) {
+	out := jsonReport{
+		DurationNS: int64(r.Duration),
+		Error:      errorString(r.Err),
+		Fields:     make([]jsonFieldReport, 0, len(r.Fields)),
+	}
+	for _, f := range r.Fields {
+		out.Fields = append(out.Fields, jsonFieldReport{
+			Field:      f.Field,
+			Key:        f.Key,
+			Origin:     f.Origin,
+			Source:     f.Source,
+			File:       f.File,
+			Refs:       f.Refs,
+			Value:      f.Redacted(),
+			Sensitive:  f.Sensitive,
+			DurationNS: int64(f.Duration),
+			Error:      errorString(f.Err),
+		})
+	}
+	return json.Marshal(out)
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
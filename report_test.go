@@ -0,0 +1,221 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	type config struct {
+		Host     string `env:"HOST"`
+		Port     int    `env:"PORT" envDefault:"3000"`
+		Password string `env:"PASSWORD,sensitive"`
+		Unset    string `env:"UNSET"`
+		Database struct {
+			URL string `env:"URL" envDefault:"postgres://${APP_HOST}" envExpand:"true"`
+		} `envPrefix:"DB_"`
+		Untagged string
+	}
+	defer os.Clearenv()
+	os.Setenv("APP_HOST", "localhost")
+	os.Setenv("APP_PASSWORD", "hunter2")
+
+	var cfg config
+	var report Report
+	require.NoError(t, ParsePrefix("APP_", &cfg, WithReport(&report)))
+	assert.NoError(t, report.Err)
+	assert.True(t, report.Duration > 0)
+
+	require.Len(t, report.Fields, 5)
+	assert.Equal(t, "Host", report.Fields[0].Field)
+	assert.Equal(t, "APP_HOST", report.Fields[0].Key)
+	assert.Equal(t, OriginEnv, report.Fields[0].Origin)
+	assert.Equal(t, "localhost", report.Fields[0].Value)
+
+	assert.Equal(t, "Port", report.Fields[1].Field)
+	assert.Equal(t, OriginDefault, report.Fields[1].Origin)
+
+	assert.Equal(t, "Password", report.Fields[2].Field)
+	assert.Equal(t, "hunter2", report.Fields[2].Value)
+	assert.Equal(t, "*****", report.Fields[2].Redacted())
+	assert.True(t, report.Fields[2].Sensitive)
+
+	assert.Equal(t, "Unset", report.Fields[3].Field)
+	assert.Equal(t, Origin(""), report.Fields[3].Origin)
+
+	assert.Equal(t, "Database.URL", report.Fields[4].Field)
+	assert.Equal(t, "APP_DB_URL", report.Fields[4].Key)
+	assert.Equal(t, "postgres://localhost", report.Fields[4].Value)
+}
+
+func TestReportErrors(t *testing.T) {
+	type config struct {
+		Port int    `env:"PORT"`
+		Host string `env:"HOST"`
+	}
+	defer os.Clearenv()
+	os.Setenv("PORT", "nope")
+
+	var cfg config
+	var report Report
+	err := Parse(&cfg, WithReport(&report))
+	require.Error(t, err)
+	assert.Equal(t, err, report.Err)
+	require.Len(t, report.Fields, 1)
+	assert.Equal(t, err, report.Fields[0].Err)
+}
+
+func TestReportJSON(t *testing.T) {
+	type config struct {
+		Port     int    `env:"PORT" envDefault:"3000"`
+		Password string `env:"PASSWORD,sensitive"`
+		Count    int    `env:"COUNT"`
+	}
+	defer os.Clearenv()
+	os.Setenv("PASSWORD", "hunter2")
+	os.Setenv("COUNT", "many")
+
+	var cfg config
+	var report Report
+	assert.Error(t, Parse(&cfg, WithReport(&report)))
+
+	b, err := json.Marshal(report)
+	require.NoError(t, err)
+	durations := regexp.MustCompile(`"duration_ns":\d+`)
+	assert.JSONEq(t, `{
+		"duration_ns": 0,
+		"error": "env: parse error on field \"Count\" of type \"int\" (COUNT=\"many\"): strconv.ParseInt: parsing \"many\": invalid syntax",
+		"fields": [
+			{"field": "Port", "key": "PORT", "origin": "default", "value": "3000", "duration_ns": 0},
+			{"field": "Password", "key": "PASSWORD", "origin": "env", "source": "os", "value": "*****", "sensitive": true, "duration_ns": 0},
+			{"field": "Count", "key": "COUNT", "origin": "env", "source": "os", "value": "many", "duration_ns": 0,
+			 "error": "env: parse error on field \"Count\" of type \"int\" (COUNT=\"many\"): strconv.ParseInt: parsing \"many\": invalid syntax"}
+		]
+	}`, durations.ReplaceAllString(string(b), `"duration_ns":0`))
+	assert.NotContains(t, string(b), "hunter2")
+}
+
+func TestRedactParseErrors(t *testing.T) {
+	type flag struct {
+		Enabled bool `env:"ENABLED,sensitive"`
+	}
+	err := Parse(&flag{}, WithSource(MapSource{"ENABLED": "n"}))
+	assert.EqualError(t, err, `env: parse error on field "Enabled" of type "bool" (ENABLED="*****"): strconv.ParseBool: parsing "*****": invalid syntax`)
+
+	type pins struct {
+		PINs []int `env:"PINS,sensitive"`
+	}
+	err = Parse(&pins{}, WithSource(MapSource{"PINS": "1234,12x4"}))
+	assert.EqualError(t, err, `env: parse error on field "PINs" of type "[]int" (PINS="*****"): strconv.ParseInt: parsing "*****": invalid syntax`)
+
+	type timeout struct {
+		Timeout time.Duration `env:"TIMEOUT,sensitive"`
+	}
+	err = Parse(&timeout{}, WithSource(MapSource{"TIMEOUT": "hunter2"}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `time: invalid duration "*****"`)
+	assert.Contains(t, err.Error(), `of type "time.Duration" (TIMEOUT="*****")`)
+
+	// "e" shows up unquoted in "invalid duration", so the message goes
+	err = Parse(&timeout{}, WithSource(MapSource{"TIMEOUT": "e"}))
+	assert.EqualError(t, err, `env: parse error on field "Timeout" of type "time.Duration" (TIMEOUT="*****"): invalid value`)
+}
+
+type secretLevel int
+
+func (l *secretLevel) UnmarshalText(text []byte) error {
+	return fmt.Errorf("unknown level %q", text)
+}
+
+func TestRedactParseErrorElements(t *testing.T) {
+	type slice struct {
+		T []time.Duration `env:"T,sensitive"`
+	}
+	err := Parse(&slice{}, WithSource(MapSource{"T": "1s,hunter2"}))
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "hunter2")
+	assert.Contains(t, err.Error(), `time: invalid duration "*****"`)
+
+	type nested struct {
+		T [][]time.Duration `env:"T,sensitive"`
+	}
+	err = Parse(&nested{}, WithSource(MapSource{"T": "1s|hunter2,2s"}))
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "hunter2")
+
+	type mapping struct {
+		T map[string]time.Duration `env:"T,sensitive"`
+	}
+	err = Parse(&mapping{}, WithSource(MapSource{"T": "web:1s,db:hunter2"}))
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "hunter2")
+	assert.Contains(t, err.Error(), `time: invalid duration "*****"`)
+
+	err = Parse(&mapping{}, WithSource(MapSource{"T": "web:1s,hunter2"}))
+	assert.EqualError(t, err, `env: parse error on field "T" of type "map[string]time.Duration" (T="*****"): invalid map item: "*****"`)
+
+	type unmarshaler struct {
+		Levels []secretLevel `env:"LEVELS,sensitive"`
+	}
+	err = Parse(&unmarshaler{}, WithSource(MapSource{"LEVELS": "hunter2,debug"}))
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "hunter2")
+	assert.Contains(t, err.Error(), `unknown level "*****"`)
+
+	type tier int
+	type custom struct {
+		Tier tier `env:"TIER,sensitive"`
+	}
+	funcs := map[reflect.Type]ParserFunc{
+		reflect.TypeOf(tier(0)): func(v string) (interface{}, error) {
+			return nil, fmt.Errorf("unknown tier %s", v)
+		},
+	}
+	err = Parse(&custom{}, WithSource(MapSource{"TIER": "hunter2"}), WithFuncs(funcs))
+	assert.EqualError(t, err, `env: parse error on field "Tier" of type "env.tier" (TIER="*****"): invalid value`)
+}
+
+func TestRedactor(t *testing.T) {
+	type config struct {
+		Token  string `env:"TOKEN,sensitive"`
+		PIN    int    `env:"PIN,sensitive"`
+		Public string `env:"PUBLIC"`
+	}
+	defer os.Clearenv()
+	os.Setenv("TOKEN", "abcdef123456")
+	os.Setenv("PIN", "12x4")
+	os.Setenv("PUBLIC", "hello")
+
+	lastFour := func(field FieldParams, value string) string {
+		if len(value) <= 4 {
+			return "****"
+		}
+		return "****" + value[len(value)-4:]
+	}
+
+	var cfg config
+	var report Report
+	err := Parse(&cfg, WithReport(&report), WithRedactor(lastFour))
+	assert.EqualError(t, err, `env: parse error on field "PIN" of type "int" (PIN="****"): strconv.ParseInt: parsing "****": invalid syntax`)
+	require.Len(t, report.Fields, 2)
+	assert.Equal(t, "****3456", report.Fields[0].Redacted())
+	assert.Equal(t, "****", report.Fields[1].Redacted())
+
+	b, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "12x4")
+	assert.NotContains(t, string(b), "abcdef")
+
+	os.Setenv("PIN", "1234")
+	require.NoError(t, Parse(&cfg, WithReport(&report)))
+	assert.Equal(t, "*****", report.Fields[0].Redacted())
+	assert.Equal(t, "hello", report.Fields[2].Redacted())
+}
@@ -0,0 +1,87 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// deferredField is a field whose value refers to other variables, and is
+// therefore resolved once all plain fields have been.
+type deferredField struct {
+	prefix   string
+	path     string
+	field    reflect.Value
+	sf       reflect.StructField
+	key      string
+	resolved bool
+}
+
+func (p *parser) deferField(prefix, path string, field reflect.Value, sf reflect.StructField) {
+	params, _ := p.fieldParams(prefix, path, sf)
+	p.deferred = append(p.deferred, &deferredField{
+		prefix: prefix,
+		path:   path,
+		field:  field,
+		sf:     sf,
+		key:    params.Key,
+	})
+}
+
+// resolveDeferred resolves the deferred fields, in declaration order unless a
+// field refers to another one, in which case the latter is resolved first.
+func (p *parser) resolveDeferred() error {
+	for _, d := range p.deferred {
+		if err := p.resolve(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parser) resolve(d *deferredField) error {
+	if d.resolved {
+		return nil
+	}
+	for i, key := range p.expanding {
+		if key == d.key {
+			cycle := append(append([]string{}, p.expanding[i:]...), d.key)
+			return fmt.Errorf("env: cycle detected in expansion: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	p.expanding = append(p.expanding, d.key)
+	err := p.parseField(d.prefix, d.path, d.field, d.sf)
+	p.expanding = p.expanding[:len(p.expanding)-1]
+	d.resolved = true
+	return err
+}
+
+// expand is the mapping function used by os.Expand to replace ${var}. Fields
+// referred to are resolved first, so that their value, or their default,
+// is used; other variables, and a field referring to its own key, are read
+// from the Source. It also reports whether the variable is defined.
+func (p *parser) expand(key string) (string, bool) {
+	if n := len(p.expanding); n > 0 && p.expanding[n-1] == key {
+		return p.expandSource(key)
+	}
+	for _, d := range p.deferred {
+		if d.key != key || d.resolved {
+			continue
+		}
+		if err := p.resolve(d); err != nil && p.expandErr == nil {
+			p.expandErr = err
+		}
+	}
+	if value, ok := p.values[key]; ok {
+		return value, true
+	}
+	return p.expandSource(key)
+}
+
+func (p *parser) expandSource(key string) (string, bool) {
+	value, exists, err := p.lookupSource(key)
+	if err != nil && p.expandErr == nil {
+		p.expandErr = err
+	}
+	return value, exists
+}
@@ -0,0 +1,157 @@
+package env
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandSiblingsAnyOrder(t *testing.T) {
+	type config struct {
+		URL      string `env:"URL" envDefault:"${SCHEME}://${HOST_PORT}/${DB}" envExpand:"true"`
+		HostPort string `env:"HOST_PORT" envDefault:"${HOST}:${PORT}" envExpand:"true"`
+		Scheme   string `env:"SCHEME" envDefault:"postgres"`
+		Host     string `env:"HOST" envDefault:"localhost"`
+		Port     int    `env:"PORT" envDefault:"5432"`
+		DB       string `env:"DB"`
+	}
+	defer os.Clearenv()
+	os.Setenv("PORT", "6432")
+	os.Setenv("DB", "app")
+
+	var cfg config
+	assert.NoError(t, Parse(&cfg))
+	assert.Equal(t, "localhost:6432", cfg.HostPort)
+	assert.Equal(t, "postgres://localhost:6432/app", cfg.URL)
+}
+
+func TestExpandSiblingsNested(t *testing.T) {
+	type config struct {
+		Inner struct {
+			URL string `env:"URL" envDefault:"http://${APP_HOST}" envExpand:"true"`
+		} `envPrefix:"INNER_"`
+		Host string `env:"HOST" envDefault:"example.com"`
+	}
+	defer os.Clearenv()
+
+	var cfg config
+	assert.NoError(t, ParsePrefix("APP_", &cfg))
+	assert.Equal(t, "http://example.com", cfg.Inner.URL)
+}
+
+func TestExpandCycle(t *testing.T) {
+	type config struct {
+		A string `env:"A" envDefault:"${B}" envExpand:"true"`
+		B string `env:"B" envDefault:"${C}" envExpand:"true"`
+		C string `env:"C" envDefault:"${A}" envExpand:"true"`
+	}
+	defer os.Clearenv()
+
+	var cfg config
+	assert.EqualError(t, Parse(&cfg), "env: cycle detected in expansion: A -> B -> C -> A")
+
+}
+
+func TestExpandSelf(t *testing.T) {
+	type config struct {
+		Path string `env:"MY_PATH" envDefault:"${MY_PATH}:/opt/bin" envExpand:"true"`
+	}
+	defer os.Clearenv()
+
+	var cfg config
+	assert.NoError(t, Parse(&cfg))
+	assert.Equal(t, ":/opt/bin", cfg.Path)
+
+	os.Setenv("MY_PATH", "/bin:${HOME}")
+	os.Setenv("HOME", "/root")
+	assert.NoError(t, Parse(&cfg))
+	assert.Equal(t, "/bin:/root", cfg.Path)
+}
+
+func TestStrictExpansion(t *testing.T) {
+	type config struct {
+		DSN  string `env:"DSN" envDefault:"postgres://${DB_USER}@${DB_HOST}:${DB_PORT}/app" envExpand:"true"`
+		Port int    `env:"DB_PORT" envDefault:"5432"`
+		User string `env:"DB_USER"`
+	}
+	var cfg config
+	assert.NoError(t, Parse(&cfg, WithSource(MapSource{"DB_USER": "app", "DB_HOST": "db"}), WithStrictExpansion()))
+	assert.Equal(t, "postgres://app@db:5432/app", cfg.DSN)
+
+	err := Parse(&config{}, WithSource(MapSource{"DB_USER": "app"}), WithStrictExpansion())
+	assert.EqualError(t, err, `env: environment variable "DB_HOST" referred to by "DSN" is not set`)
+
+	err = Parse(&config{}, WithSource(MapSource{"DB_HOST": "db"}), WithStrictExpansion())
+	assert.EqualError(t, err, `env: environment variable "DB_USER" referred to by "DSN" is not set`)
+
+	assert.NoError(t, Parse(&cfg, WithSource(MapSource{})))
+	assert.Equal(t, "postgres://@:5432/app", cfg.DSN)
+}
+
+func TestExpandOperators(t *testing.T) {
+	type config struct {
+		Host    string `env:"HOST" envDefault:"${DB_HOST:-localhost}" envExpand:"true"`
+		Addr    string `env:"ADDR" envDefault:"${DB_HOST-$FALLBACK}:${DB_PORT:-5432}" envExpand:"true"`
+		Auth    string `env:"AUTH" envDefault:"${DB_USER:+$DB_USER@}${DB_HOST:?must be set}" envExpand:"true"`
+		Options string `env:"OPTIONS" envDefault:"${DB_SSL+sslmode=$DB_SSL}" envExpand:"true"`
+	}
+	var cfg config
+	assert.NoError(t, Parse(&cfg, WithSource(MapSource{"DB_HOST": "db", "DB_USER": "app", "DB_SSL": ""})))
+	assert.Equal(t, config{Host: "db", Addr: "db:5432", Auth: "app@db", Options: "sslmode="}, cfg)
+
+	cfg = config{}
+	err := Parse(&cfg, WithSource(MapSource{"DB_HOST": "", "FALLBACK": "fallback"}))
+	assert.EqualError(t, err, "env: DB_HOST: must be set")
+
+	type short struct {
+		URL string `env:"URL" envDefault:"${HOST:?}" envExpand:"true"`
+	}
+	err = Parse(&short{}, WithSource(MapSource{}))
+	assert.EqualError(t, err, `env: environment variable "HOST" referred to by "URL" is not set`)
+
+	type strict struct {
+		Host string `env:"HOST" envDefault:"${DB_HOST:-localhost}${DB_SUFFIX:+.local}" envExpand:"true"`
+	}
+	s := strict{}
+	assert.NoError(t, Parse(&s, WithSource(MapSource{}), WithStrictExpansion()))
+	assert.Equal(t, "localhost", s.Host)
+}
+
+func TestExpandWindowsDialect(t *testing.T) {
+	type config struct {
+		Path  string `env:"DATA_PATH" envDefault:"%ProgramData%\\app;${HOME}" envExpand:"true"`
+		Ratio string `env:"RATIO" envDefault:"100% of %%LIMIT%% is %LIMIT% (50%)" envExpand:"true"`
+	}
+	src := MapSource{"ProgramData": `C:\ProgramData`, "HOME": `C:\Users\app`, "LIMIT": "10"}
+
+	var cfg config
+	assert.NoError(t, Parse(&cfg, WithSource(src), WithExpansionDialect(WindowsDialect)))
+	assert.Equal(t, `C:\ProgramData\app;C:\Users\app`, cfg.Path)
+	assert.Equal(t, "100% of %LIMIT% is 10 (50%)", cfg.Ratio)
+
+	cfg = config{}
+	assert.NoError(t, Parse(&cfg, WithSource(src)))
+	assert.Equal(t, `%ProgramData%\app;C:\Users\app`, cfg.Path)
+}
+
+func TestRecursiveExpansion(t *testing.T) {
+	type config struct {
+		URL string `env:"URL" envDefault:"${BASE_URL}/api" envExpand:"true"`
+	}
+	src := MapSource{"BASE_URL": "https://${HOST}", "HOST": "${NAME}.${DOMAIN}", "NAME": "app", "DOMAIN": "example.com"}
+
+	var cfg config
+	assert.NoError(t, Parse(&cfg, WithSource(src)))
+	assert.Equal(t, "https://${HOST}/api", cfg.URL)
+
+	assert.NoError(t, Parse(&cfg, WithSource(src), WithRecursiveExpansion(2)))
+	assert.Equal(t, "https://app.example.com/api", cfg.URL)
+
+	err := Parse(&cfg, WithSource(src), WithRecursiveExpansion(1))
+	assert.EqualError(t, err, `env: expansion of "URL" exceeds the maximum depth of 1: BASE_URL -> HOST`)
+
+	src = MapSource{"BASE_URL": "https://${HOST}", "HOST": "${BASE_URL}"}
+	err = Parse(&cfg, WithSource(src), WithRecursiveExpansion(10))
+	assert.EqualError(t, err, "env: cycle detected in expansion: BASE_URL -> HOST -> BASE_URL")
+}
@@ -0,0 +1,84 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RetryPolicy controls how lookups in a ContextSource, such as a remote
+// secret store, are retried when they fail.
+type RetryPolicy struct {
+	// Attempts is the maximum number of lookups of a variable. Zero or one
+	// disable retries.
+	Attempts int
+
+	// Backoff is the delay before the first retry, doubled after each
+	// retry up to MaxBackoff, if set.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// Timeout, if positive, bounds each lookup. A lookup still running
+	// after Timeout is abandoned, even if the Source ignores its context.
+	Timeout time.Duration
+}
+
+// WithRetry makes Parse retry failed lookups in a ContextSource according to
+// policy, so that a transient failure of a remote store at startup does not
+// fail Parse, and a hung store does not block it forever.
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *Options) {
+		o.Retry = policy
+	}
+}
+
+// lookupRetry looks key up in a ContextSource, applying the RetryPolicy.
+func (p *parser) lookupRetry(cs ContextSource, key string) (value string, exists bool, from Source, err error) {
+	rec := &sourceRecorder{}
+	ctx := withSourceRecorder(p.ctx, rec)
+	backoff := p.Retry.Backoff
+	for attempt := 1; ; attempt++ {
+		value, exists, err = p.lookupTimeout(ctx, cs, key)
+		if err == nil || attempt >= p.Retry.Attempts {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+		case <-p.ctx.Done():
+			return "", false, nil, p.ctx.Err()
+		}
+		backoff *= 2
+		if p.Retry.MaxBackoff > 0 && backoff > p.Retry.MaxBackoff {
+			backoff = p.Retry.MaxBackoff
+		}
+	}
+	if err != nil && p.Retry.Attempts > 1 {
+		err = fmt.Errorf("%w (after %d attempts)", err, p.Retry.Attempts)
+	}
+	return value, exists, rec.source(cs), err
+}
+
+func (p *parser) lookupTimeout(ctx context.Context, cs ContextSource, key string) (string, bool, error) {
+	if p.Retry.Timeout <= 0 {
+		return cs.LookupContext(ctx, key)
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.Retry.Timeout)
+	defer cancel()
+
+	type result struct {
+		value  string
+		exists bool
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, exists, err := cs.LookupContext(ctx, key)
+		done <- result{value, exists, err}
+	}()
+	select {
+	case r := <-done:
+		return r.value, r.exists, r.err
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	}
+}
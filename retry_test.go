@@ -0,0 +1,96 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type flakySource struct {
+	failures int
+	calls    int
+	hang     bool
+}
+
+func (s *flakySource) Lookup(key string) (string, bool) {
+	v, ok, _ := s.LookupContext(context.Background(), key)
+	return v, ok
+}
+
+func (s *flakySource) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	s.calls++
+	if s.hang {
+		<-ctx.Done()
+		return "", false, ctx.Err()
+	}
+	if s.calls <= s.failures {
+		return "", false, errors.New("unavailable")
+	}
+	return "secret", true, nil
+}
+
+func TestRetry(t *testing.T) {
+	type config struct {
+		Password string `env:"PASSWORD"`
+	}
+	src := &flakySource{failures: 2}
+	var cfg config
+	err := Parse(&cfg, WithSource(src), WithRetry(RetryPolicy{Attempts: 3, Backoff: time.Millisecond}))
+	require.NoError(t, err)
+	assert.Equal(t, "secret", cfg.Password)
+	assert.Equal(t, 3, src.calls)
+
+	src = &flakySource{failures: 3}
+	err = Parse(&cfg, WithSource(src), WithRetry(RetryPolicy{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}))
+	assert.EqualError(t, err, `env: could not look up "PASSWORD": unavailable (after 3 attempts)`)
+
+	src = &flakySource{failures: 1}
+	err = Parse(&cfg, WithSource(src))
+	assert.EqualError(t, err, `env: could not look up "PASSWORD": unavailable`)
+	assert.Equal(t, 1, src.calls)
+}
+
+func TestRetryTimeout(t *testing.T) {
+	type config struct {
+		Password string `env:"PASSWORD"`
+	}
+	src := &flakySource{hang: true}
+	var cfg config
+	err := Parse(&cfg, WithSource(src), WithRetry(RetryPolicy{Timeout: 10 * time.Millisecond}))
+	assert.EqualError(t, err, `env: could not look up "PASSWORD": context deadline exceeded`)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+// stuckSource blocks every lookup until release is closed, ignoring its
+// context.
+type stuckSource struct {
+	release chan struct{}
+}
+
+func (s stuckSource) Lookup(key string) (string, bool) {
+	<-s.release
+	return "", false
+}
+
+func (s stuckSource) LookupContext(_ context.Context, key string) (string, bool, error) {
+	<-s.release
+	return "", false, nil
+}
+
+func TestRetryTimeoutIgnoredContext(t *testing.T) {
+	type config struct {
+		Password string `env:"PASSWORD"`
+	}
+	src := stuckSource{release: make(chan struct{})}
+	t.Cleanup(func() { close(src.release) })
+
+	start := time.Now()
+	var cfg config
+	err := Parse(&cfg, WithSource(src), WithRetry(RetryPolicy{Timeout: 10 * time.Millisecond}))
+	assert.EqualError(t, err, `env: could not look up "PASSWORD": context deadline exceeded`)
+	assert.True(t, time.Since(start) < time.Second, "Parse waited for the lookup")
+}
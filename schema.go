@@ -0,0 +1,170 @@
+package env
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+// jsonSchema is the subset of JSON Schema written by JSONSchema.
+type jsonSchema struct {
+	Schema            string                    `json:"$schema"`
+	Type              string                    `json:"type"`
+	Properties        map[string]schemaProperty `json:"properties"`
+	PatternProperties map[string]schemaProperty `json:"patternProperties,omitempty"`
+	Required          []string                  `json:"required,omitempty"`
+	// AllOf requires, for each required chunked variable, either the
+	// variable or its first chunk.
+	AllOf []schemaAlternatives `json:"allOf,omitempty"`
+}
+
+type schemaAlternatives struct {
+	AnyOf []schemaRequired `json:"anyOf"`
+}
+
+type schemaRequired struct {
+	Required []string `json:"required"`
+}
+
+type schemaProperty struct {
+	Type        string   `json:"type"`
+	Description string   `json:"description,omitempty"`
+	Default     *string  `json:"default,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+	Format      string   `json:"format,omitempty"`
+	WriteOnly   bool     `json:"writeOnly,omitempty"`
+}
+
+// JSONSchema returns a JSON Schema (draft 2020-12) describing the
+// environment Parse expects for v, a struct or a pointer to a struct, so
+// that external validators, UIs and platform teams can check environments
+// without importing the Go code.
+//
+// The schema validates the environment as an object of strings, keyed by
+// variable name: booleans are constrained to the values strconv.ParseBool
+// accepts, numbers and durations to a pattern, unsigned integers excluding
+// negative values, and URLs and times to a format. JSON Schema cannot bound
+// numbers written as strings, so the range of integers is only checked by
+// Parse. Types registered with RegisterAlias are constrained to their
+// allowed values. Values that are not written as their type is parsed, those
+// of fields with the `file`, `relative` or `envExpand` options, cleaned up or
+// decoded before parsing, or of types given a parser with WithFuncs, are
+// only constrained to be strings. The chunks of fields
+// with the `chunked` option are described by a pattern property, and either
+// the variable or its first chunk is required.
+//
+// Required variables are listed as such, and sensitive ones are marked as
+// writeOnly. Other variables are allowed, as the environment usually holds
+// more than the configuration.
+func JSONSchema(v interface{}, opts ...Option) ([]byte, error) {
+	infos, err := Describe(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	funcMap := newParser(opts).FuncMap
+	schema := jsonSchema{
+		Schema:     "https://json-schema.org/draft/2020-12/schema",
+		Type:       "object",
+		Properties: make(map[string]schemaProperty, len(infos)),
+	}
+	for _, info := range infos {
+		prop := schemaProperty{Type: "string"}
+		if !rawValue(info.FieldParams) {
+			prop = schemaFor(info.Type, funcMap)
+		}
+		prop.Description = info.Docs
+		prop.WriteOnly = info.Sensitive
+		if info.HasDefaultValue {
+			def := info.DefaultValue
+			prop.Default = &def
+		}
+		schema.Properties[info.Key] = prop
+		if info.Chunked {
+			if schema.PatternProperties == nil {
+				schema.PatternProperties = map[string]schemaProperty{}
+			}
+			schema.PatternProperties["^"+regexp.QuoteMeta(info.Key)+"_[0-9]+$"] = schemaProperty{
+				Type:        "string",
+				Description: "Chunk of " + info.Key,
+				WriteOnly:   info.Sensitive,
+			}
+		}
+		switch {
+		case info.Required && info.Chunked:
+			schema.AllOf = append(schema.AllOf, schemaAlternatives{AnyOf: []schemaRequired{
+				{Required: []string{info.Key}},
+				{Required: []string{info.Key + "_0"}},
+			}})
+		case info.Required:
+			schema.Required = append(schema.Required, info.Key)
+		}
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+const (
+	intPattern      = `^[+-]?[0-9]+$`
+	uintPattern     = `^[0-9]+$`
+	floatPattern    = `^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?$`
+	durationPattern = `^[+-]?(0|([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h))+$`
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// boolValues are the values accepted by strconv.ParseBool.
+var boolValues = []string{"1", "t", "T", "TRUE", "true", "True", "0", "f", "F", "FALSE", "false", "False"}
+
+// rawValue reports whether the values of the variable of params are not
+// written as its type is parsed: they name a file, are relative times, are
+// split into chunks, refer to other variables, or are cleaned up or decoded
+// first.
+func rawValue(params FieldParams) bool {
+	return params.LoadFile || params.Relative || params.Chunked || params.Expand ||
+		params.Trim || params.Unquote || params.Multiline || len(params.Encodings) > 0
+}
+
+// schemaFor returns the constraints on the values of variables of type t,
+// leaving those of the types in funcMap, which have custom parsers,
+// unconstrained.
+func schemaFor(t reflect.Type, funcMap map[reflect.Type]ParserFunc) schemaProperty {
+	prop := schemaProperty{Type: "string"}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if _, ok := funcMap[t]; ok {
+		return prop
+	}
+	if values := allowedValues(t); values != nil {
+		prop.Enum = values
+		return prop
+	}
+	switch t {
+	case reflect.TypeOf(time.Duration(0)):
+		prop.Pattern = durationPattern
+		return prop
+	case reflect.TypeOf(url.URL{}):
+		prop.Format = "uri-reference"
+		return prop
+	case reflect.TypeOf(time.Time{}):
+		prop.Format = "date-time"
+		return prop
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return prop
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		prop.Enum = boolValues
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		prop.Pattern = intPattern
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		prop.Pattern = uintPattern
+	case reflect.Float32, reflect.Float64:
+		prop.Pattern = floatPattern
+	}
+	return prop
+}
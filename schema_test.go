@@ -0,0 +1,132 @@
+package env
+
+import (
+	"net/url"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	type config struct {
+		Debug    bool          `env:"DEBUG"`
+		Port     uint16        `env:"PORT" envDefault:"8080" envDocs:"Port to listen on"`
+		Offset   int           `env:"OFFSET"`
+		Ratio    float64       `env:"RATIO"`
+		Timeout  time.Duration `env:"TIMEOUT"`
+		URL      *url.URL      `env:"URL,required"`
+		Since    time.Time     `env:"SINCE"`
+		Password string        `env:"PASSWORD,required,sensitive"`
+	}
+
+	b, err := JSONSchema(&config{}, WithPrefix("APP_"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"APP_DEBUG": {"type": "string", "enum": ["1", "t", "T", "TRUE", "true", "True", "0", "f", "F", "FALSE", "false", "False"]},
+			"APP_PORT": {"type": "string", "description": "Port to listen on", "default": "8080", "pattern": "^[0-9]+$"},
+			"APP_OFFSET": {"type": "string", "pattern": "^[+-]?[0-9]+$"},
+			"APP_RATIO": {"type": "string", "pattern": "^[+-]?([0-9]+(\\.[0-9]*)?|\\.[0-9]+)([eE][+-]?[0-9]+)?$"},
+			"APP_TIMEOUT": {"type": "string", "pattern": "^[+-]?(0|([0-9]+(\\.[0-9]*)?|\\.[0-9]+)(ns|us|µs|ms|s|m|h))+$"},
+			"APP_URL": {"type": "string", "format": "uri-reference"},
+			"APP_SINCE": {"type": "string", "format": "date-time"},
+			"APP_PASSWORD": {"type": "string", "writeOnly": true}
+		},
+		"required": ["APP_URL", "APP_PASSWORD"]
+	}`, string(b))
+
+	_, err = JSONSchema("nope")
+	assert.EqualError(t, err, "env: expected a struct or a pointer to a struct, got string")
+}
+
+func TestJSONSchemaRawValues(t *testing.T) {
+	type config struct {
+		Port      int           `env:"PORT_FILE,file"`
+		Since     time.Time     `env:"SINCE,relative"`
+		Debug     bool          `env:"DEBUG,trim"`
+		Retries   int           `env:"RETRIES,unquote"`
+		Timeout   time.Duration `env:"TIMEOUT,multiline"`
+		Ratio     float64       `env:"RATIO,base64"`
+		Workers   uint          `env:"WORKERS" envEncoding:"gzip,base64"`
+		Args      []int         `env:"ARGS,shellWords"`
+		Bundle    url.URL       `env:"BUNDLE,chunked,required,sensitive"`
+		Expanded  int           `env:"EXPANDED" envExpand:"true"`
+		Unchanged int           `env:"UNCHANGED"`
+	}
+
+	b, err := JSONSchema(&config{}, WithPrefix("APP_"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"APP_PORT_FILE": {"type": "string"},
+			"APP_SINCE": {"type": "string"},
+			"APP_DEBUG": {"type": "string"},
+			"APP_RETRIES": {"type": "string"},
+			"APP_TIMEOUT": {"type": "string"},
+			"APP_RATIO": {"type": "string"},
+			"APP_WORKERS": {"type": "string"},
+			"APP_ARGS": {"type": "string"},
+			"APP_BUNDLE": {"type": "string", "writeOnly": true},
+			"APP_EXPANDED": {"type": "string"},
+			"APP_UNCHANGED": {"type": "string", "pattern": "^[+-]?[0-9]+$"}
+		},
+		"patternProperties": {
+			"^APP_BUNDLE_[0-9]+$": {"type": "string", "description": "Chunk of APP_BUNDLE", "writeOnly": true}
+		},
+		"allOf": [
+			{"anyOf": [{"required": ["APP_BUNDLE"]}, {"required": ["APP_BUNDLE_0"]}]}
+		]
+	}`, string(b))
+
+	b, err = JSONSchema(&config{}, WithTrimSpace())
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "pattern\"")
+}
+
+func TestJSONSchemaTypes(t *testing.T) {
+	type level int
+	type config struct {
+		Format logFormat `env:"FORMAT"`
+		Port   *port     `env:"PORT"`
+		Level  level     `env:"LEVEL"`
+	}
+	funcs := WithFuncs(map[reflect.Type]ParserFunc{
+		reflect.TypeOf(level(0)): func(v string) (interface{}, error) {
+			return level(len(v)), nil
+		},
+	})
+
+	b, err := JSONSchema(&config{}, funcs)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"FORMAT": {"type": "string", "enum": ["json", "text"]},
+			"PORT": {"type": "string", "enum": ["80", "443"]},
+			"LEVEL": {"type": "string"}
+		}
+	}`, string(b))
+}
+
+func TestJSONSchemaPatterns(t *testing.T) {
+	for pattern, values := range map[string]map[string]bool{
+		intPattern:      {"42": true, "-1": true, "+7": true, "1.5": false, "": false},
+		uintPattern:     {"42": true, "-1": false, "+7": false},
+		floatPattern:    {"1": true, "-1.5": true, ".5": true, "1e-3": true, "1.": true, "e3": false, "1.2.3": false},
+		durationPattern: {"0": true, "1h30m": true, "-1.5s": true, "300ms": true, "2µs": true, "1": false, "1d": false},
+	} {
+		re := regexp.MustCompile(pattern)
+		for value, ok := range values {
+			assert.Equal(t, ok, re.MatchString(value), "%q against %s", value, pattern)
+		}
+	}
+}
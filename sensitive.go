@@ -0,0 +1,41 @@
+package env
+
+import "reflect"
+
+// Sensitive is implemented by types whose values are secrets. Fields of such
+// types, or pointers to them, are treated as if they were tagged with the
+// `sensitive` option.
+type Sensitive interface {
+	Sensitive() bool
+}
+
+// Secret is a string that is always sensitive, for fields that should be
+// redacted whatever their tags, e.g. API keys in shared config structs.
+type Secret string
+
+// Sensitive implements the Sensitive interface.
+func (Secret) Sensitive() bool {
+	return true
+}
+
+var sensitiveType = reflect.TypeOf((*Sensitive)(nil)).Elem()
+
+// isSensitive reports whether values of type t are secrets.
+func isSensitive(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface || !reflect.PtrTo(t).Implements(sensitiveType) {
+		return false
+	}
+	return reflect.New(t).Interface().(Sensitive).Sensitive()
+}
+
+// WithUnredacted makes Marshal, MarshalVars and ToEnviron write the values
+// of sensitive fields as they are, instead of masking them with the
+// Redactor, e.g. to hand secrets to a child process.
+func WithUnredacted() Option {
+	return func(o *Options) {
+		o.Unredacted = true
+	}
+}
@@ -0,0 +1,69 @@
+package env
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sensitiveConfig struct {
+	Host     string  `env:"HOST"`
+	Password string  `env:"PASSWORD,sensitive"`
+	Token    Secret  `env:"TOKEN"`
+	Key      *Secret `env:"KEY"`
+	Empty    Secret  `env:"EMPTY"`
+}
+
+func TestSecret(t *testing.T) {
+	var cfg sensitiveConfig
+	var r Report
+	source := MapSource{"HOST": "localhost", "TOKEN": "t0ken", "KEY": "k3y"}
+	require.NoError(t, Parse(&cfg, WithSource(source), WithReport(&r)))
+	assert.Equal(t, Secret("t0ken"), cfg.Token)
+	assert.Equal(t, Secret("k3y"), *cfg.Key)
+
+	redacted := map[string]string{}
+	for _, f := range r.Fields {
+		redacted[f.Key] = f.Redacted()
+	}
+	assert.Equal(t, "localhost", redacted["HOST"])
+	assert.Equal(t, "*****", redacted["TOKEN"])
+	assert.Equal(t, "*****", redacted["KEY"])
+}
+
+func TestMarshalRedacted(t *testing.T) {
+	key := Secret("k3y")
+	cfg := sensitiveConfig{Host: "localhost", Password: "hunter2", Token: "t0ken", Key: &key}
+
+	vars, err := Marshal(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"HOST":     "localhost",
+		"PASSWORD": "*****",
+		"TOKEN":    "*****",
+		"KEY":      "*****",
+		"EMPTY":    "",
+	}, vars)
+
+	environ, err := ToEnviron(cfg, WithRedactor(func(field FieldParams, value string) string {
+		return "<" + field.Key + ">"
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"HOST=localhost", "PASSWORD=<PASSWORD>", "TOKEN=<TOKEN>", "KEY=<KEY>", "EMPTY=<EMPTY>"}, environ)
+
+	vars, err = Marshal(cfg, WithUnredacted())
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", vars["PASSWORD"])
+	assert.Equal(t, "t0ken", vars["TOKEN"])
+}
+
+func TestSetUnredacted(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+
+	require.NoError(t, Set(sensitiveConfig{Password: "hunter2", Token: "t0ken"}))
+	assert.Equal(t, "hunter2", os.Getenv("PASSWORD"))
+	assert.Equal(t, "t0ken", os.Getenv("TOKEN"))
+}
@@ -0,0 +1,70 @@
+package env
+
+import (
+	"errors"
+	"strings"
+)
+
+// splitShellWords splits value into words as a POSIX shell does, without
+// any expansion: words are separated by white space, which single quotes,
+// double quotes or a backslash make part of a word, so that `a "b c" d` is
+// split into a, "b c" and d.
+func splitShellWords(value string) ([]string, error) {
+	var words []string
+	var b strings.Builder
+	inWord := false
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case ' ', '\t', '\n', '\r':
+			if inWord {
+				words = append(words, b.String())
+				b.Reset()
+				inWord = false
+			}
+		case '\\':
+			i++
+			if i == len(value) {
+				return nil, errors.New("unterminated escape at the end of the value")
+			}
+			b.WriteByte(value[i])
+			inWord = true
+		case '\'':
+			end := strings.IndexByte(value[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated single quote")
+			}
+			b.WriteString(value[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case '"':
+			// within double quotes, backslashes only escape the
+			// characters that are special there
+			for i++; i < len(value) && value[i] != '"'; i++ {
+				if value[i] == '\\' && i+1 < len(value) && strings.IndexByte("\"\\$`", value[i+1]) >= 0 {
+					i++
+				}
+				b.WriteByte(value[i])
+			}
+			if i == len(value) {
+				return nil, errors.New("unterminated double quote")
+			}
+			inWord = true
+		default:
+			b.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, b.String())
+	}
+	return words, nil
+}
+
+// quoteShellWord quotes word, if needed, for splitShellWords to read it back
+// as a single word.
+func quoteShellWord(word string) string {
+	if word != "" && strings.Trim(word, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:,=@%+") == "" {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
@@ -0,0 +1,166 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Source provides the values of environment variables to Parse.
+type Source interface {
+	// Lookup retrieves the value of the variable named by key, and whether
+	// it is set.
+	Lookup(key string) (string, bool)
+}
+
+// ContextSource is a Source whose lookups may fail, such as one backed by a
+// remote service. Parse calls LookupContext instead of Lookup on sources
+// implementing it, and fails with the errors it returns.
+type ContextSource interface {
+	Source
+	LookupContext(ctx context.Context, key string) (string, bool, error)
+}
+
+// Enumerator is a Source able to list the variables it provides, as needed
+// by strict mode and TenantLoader.
+type Enumerator interface {
+	Source
+	// Keys returns the names of the variables starting with prefix.
+	Keys(prefix string) ([]string, error)
+}
+
+// ErrNotEnumerable is returned by Keys for sources that are not Enumerators.
+var ErrNotEnumerable = errors.New("env: source cannot list its variables")
+
+// Keys returns the sorted names of the variables of s starting with prefix.
+// Sources combined with ChainSource are listed together, skipping the ones
+// that are not Enumerators, such as remote secret stores.
+func Keys(prefix string, s Source) ([]string, error) {
+	e, ok := s.(Enumerator)
+	if !ok {
+		return nil, ErrNotEnumerable
+	}
+	keys, err := e.Keys(prefix)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// SourceFunc adapts an ordinary function to the Source interface.
+type SourceFunc func(key string) (string, bool)
+
+// Lookup calls f(key).
+func (f SourceFunc) Lookup(key string) (string, bool) {
+	return f(key)
+}
+
+// OSSource is the Source backed by the environment of the current process.
+// It is the default Source.
+type OSSource struct{}
+
+// Lookup calls os.LookupEnv.
+func (OSSource) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// Keys returns the names of the variables starting with prefix.
+func (OSSource) Keys(prefix string) ([]string, error) {
+	return append([]string{}, snapshotEnviron().withPrefix(prefix)...), nil
+}
+
+// MapSource is a Source backed by a map of variable names to values.
+type MapSource map[string]string
+
+// Lookup retrieves the value of the variable named by key.
+func (m MapSource) Lookup(key string) (string, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+// Keys returns the names of the variables starting with prefix.
+func (m MapSource) Keys(prefix string) ([]string, error) {
+	var keys []string
+	for key := range m {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// WithSource makes Parse read variables from s instead of the process
+// environment.
+func WithSource(s Source) Option {
+	return func(o *Options) {
+		o.Source = s
+	}
+}
+
+// ChainSource returns a Source looking variables up in each of the sources
+// in turn, so that earlier sources take precedence over later ones.
+func ChainSource(sources ...Source) Source {
+	return chainSource(sources)
+}
+
+type chainSource []Source
+
+func (c chainSource) Lookup(key string) (string, bool) {
+	for _, s := range c {
+		if v, ok := s.Lookup(key); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func (c chainSource) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	for _, s := range c {
+		if v, ok, err := lookupContext(ctx, s, key); err != nil || ok {
+			if ok {
+				recordSource(ctx, s)
+			}
+			return v, ok, err
+		}
+	}
+	return "", false, nil
+}
+
+func (c chainSource) Keys(prefix string) ([]string, error) {
+	seen := map[string]bool{}
+	var keys []string
+	enumerable := false
+	for _, s := range c {
+		e, ok := s.(Enumerator)
+		if !ok {
+			continue
+		}
+		enumerable = true
+		sourceKeys, err := e.Keys(prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range sourceKeys {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	if !enumerable {
+		return nil, ErrNotEnumerable
+	}
+	return keys, nil
+}
+
+// lookupContext looks key up in s, with ctx if s is a ContextSource.
+func lookupContext(ctx context.Context, s Source, key string) (string, bool, error) {
+	if cs, ok := s.(ContextSource); ok {
+		return cs.LookupContext(ctx, key)
+	}
+	v, ok := s.Lookup(key)
+	return v, ok, nil
+}
@@ -0,0 +1,77 @@
+package env
+
+import (
+	"strings"
+)
+
+// suggest returns the name of a variable of the Source which may have been
+// meant instead of key, a missing variable, e.g. DATABSE_URL for
+// DATABASE_URL, or an empty string if none is close enough or the Source
+// cannot list its variables. Only the variables starting with the prefix
+// are considered, so that the variables of other configurations sharing the
+// environment are not suggested, except for key without the prefix, in case
+// it was forgotten.
+func (p *parser) suggest(key string) string {
+	if p.environ == nil {
+		keys, err := Keys("", p.Source)
+		if err != nil {
+			return ""
+		}
+		p.environ = keys
+	}
+	prefix := ""
+	if strings.HasPrefix(key, p.Prefix) {
+		prefix = p.Prefix
+	}
+	best, bestDist := "", len(key)/4+1
+	for _, candidate := range p.environ.withPrefix(prefix) {
+		if candidate == key || p.used[candidate] {
+			continue
+		}
+		if d := editDistance(strings.ToUpper(key), strings.ToUpper(candidate)); d < bestDist {
+			best, bestDist = candidate, d
+		}
+	}
+	if best == "" && prefix != "" {
+		if _, ok := p.Source.Lookup(strings.TrimPrefix(key, prefix)); ok {
+			best = strings.TrimPrefix(key, prefix)
+		}
+	}
+	return best
+}
+
+// editDistance returns the number of insertions, deletions, substitutions
+// and transpositions of adjacent characters turning a into b.
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && prev2[j-2]+1 < cur[j] {
+				cur[j] = prev2[j-2] + 1
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
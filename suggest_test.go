@@ -0,0 +1,40 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequiredSuggestion(t *testing.T) {
+	type config struct {
+		DatabaseURL string `env:"DATABASE_URL,required"`
+	}
+	for _, tc := range []struct {
+		name   string
+		source Source
+		prefix string
+		want   string
+	}{
+		{"typo", MapSource{"DATABSE_URL": "x"}, "", `env: required environment variable "DATABASE_URL" is not set, did you mean "DATABSE_URL"?`},
+		{"case", MapSource{"database_url": "x"}, "", `env: required environment variable "DATABASE_URL" is not set, did you mean "database_url"?`},
+		{"transposition", MapSource{"APP_DATABASE_ULR": "x"}, "APP_", `env: required environment variable "APP_DATABASE_URL" is not set, did you mean "APP_DATABASE_ULR"?`},
+		{"missing prefix", MapSource{"DATABASE_URL": "x"}, "APP_", `env: required environment variable "APP_DATABASE_URL" is not set, did you mean "DATABASE_URL"?`},
+		{"other prefix", MapSource{"APQ_DATABASE_URL": "x"}, "APP_", `env: required environment variable "APP_DATABASE_URL" is not set`},
+		{"too far", MapSource{"REDIS_URL": "x"}, "", `env: required environment variable "DATABASE_URL" is not set`},
+		{"not enumerable", SourceFunc(func(string) (string, bool) { return "", false }), "", `env: required environment variable "DATABASE_URL" is not set`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var cfg config
+			assert.EqualError(t, Parse(&cfg, WithSource(tc.source), WithPrefix(tc.prefix)), tc.want)
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("PORT", "PORT"))
+	assert.Equal(t, 1, editDistance("PORT", "PROT"))
+	assert.Equal(t, 1, editDistance("DATABASE_URL", "DATABSE_URL"))
+	assert.Equal(t, 2, editDistance("HOST", "POSTS"))
+	assert.Equal(t, 4, editDistance("", "HOST"))
+}
@@ -0,0 +1,93 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// TenantLoader parses the same configuration struct T once per tenant, each
+// tenant having its own prefix: with Prefix "TENANT_", the variables
+// TENANT_A_PORT and TENANT_B_PORT configure tenants "A" and "B".
+type TenantLoader[T any] struct {
+	// Prefix is shared by the variables of all tenants.
+	Prefix string
+
+	// Options are passed to every Parse call. A WithPrefix option would be
+	// overridden by the tenant prefix.
+	Options []Option
+}
+
+// Load enumerates the tenants found in the Source, the process environment
+// unless set by Options, and parses T for each of them, keyed by tenant
+// name. A tenant is found when a variable made of Prefix, the tenant name,
+// an underscore and one of the keys of T is set.
+func (l TenantLoader[T]) Load() (map[string]T, error) {
+	keys := typeKeys(reflect.TypeOf((*T)(nil)).Elem(), "")
+	var o Options
+	for _, opt := range l.Options {
+		opt(&o)
+	}
+	if o.Source == nil {
+		o.Source = OSSource{}
+	}
+	environ, err := Keys(l.Prefix, o.Source)
+	if err != nil {
+		return nil, err
+	}
+	found := map[string]bool{}
+	for _, key := range environ {
+		rest := key[len(l.Prefix):]
+		for _, k := range keys {
+			if len(rest) > len(k)+1 && strings.HasSuffix(rest, "_"+k) {
+				found[rest[:len(rest)-len(k)-1]] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tenants := make(map[string]T, len(names))
+	for _, name := range names {
+		var cfg T
+		opts := append(append([]Option{}, l.Options...), WithPrefix(l.Prefix+name+"_"), withEnviron(environ))
+		if err := Parse(&cfg, opts...); err != nil {
+			return nil, fmt.Errorf("env: tenant %q: %w", name, err)
+		}
+		tenants[name] = cfg
+	}
+	return tenants, nil
+}
+
+// typeKeys lists the keys, relative to prefix, that parsing a zero value of
+// type t looks up.
+func typeKeys(t reflect.Type, prefix string) []string {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" || sf.Type.Kind() == reflect.Ptr || hasOption(sf, "noprefix") {
+			continue
+		}
+		envPrefix := sf.Tag.Get("envPrefix")
+		if sf.Type.Kind() == reflect.Struct && sf.Type.Name() == "" {
+			keys = append(keys, typeKeys(sf.Type, prefix+envPrefix)...)
+			continue
+		}
+		key, _ := parseKeyForOption(sf.Tag.Get("env"))
+		if key != "" {
+			keys = append(keys, prefix+key)
+		}
+		if sf.Type.Kind() == reflect.Struct {
+			keys = append(keys, typeKeys(sf.Type, prefix+envPrefix)...)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,51 @@
+package env
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantConfig struct {
+	Port int    `env:"PORT" envDefault:"3000"`
+	Host string `env:"HOST,required"`
+	DB   struct {
+		URL string `env:"URL"`
+	} `envPrefix:"DB_"`
+}
+
+func TestTenantLoader(t *testing.T) {
+	defer os.Clearenv()
+	os.Setenv("TENANT_A_HOST", "a.example.com")
+	os.Setenv("TENANT_A_PORT", "8080")
+	os.Setenv("TENANT_B_C_HOST", "bc.example.com")
+	os.Setenv("TENANT_B_C_DB_URL", "postgres://bc")
+	os.Setenv("TENANT_D_DB_URL", "postgres://d")
+	os.Setenv("OTHER_E_HOST", "e.example.com")
+
+	tenants, err := TenantLoader[tenantConfig]{Prefix: "TENANT_"}.Load()
+	assert.EqualError(t, err, `env: tenant "D": env: required environment variable "TENANT_D_HOST" is not set`)
+	assert.Nil(t, tenants)
+
+	os.Setenv("TENANT_D_HOST", "d.example.com")
+	tenants, err = TenantLoader[tenantConfig]{Prefix: "TENANT_"}.Load()
+	require.NoError(t, err)
+	require.Len(t, tenants, 3)
+	assert.Equal(t, "a.example.com", tenants["A"].Host)
+	assert.Equal(t, 8080, tenants["A"].Port)
+	assert.Equal(t, "bc.example.com", tenants["B_C"].Host)
+	assert.Equal(t, 3000, tenants["B_C"].Port)
+	assert.Equal(t, "postgres://bc", tenants["B_C"].DB.URL)
+	assert.Equal(t, "postgres://d", tenants["D"].DB.URL)
+}
+
+func TestTenantLoaderOptions(t *testing.T) {
+	defer os.Clearenv()
+	os.Setenv("TENANT_A_HOST", "a.example.com")
+	os.Setenv("TENANT_A_PROT", "8080")
+
+	_, err := TenantLoader[tenantConfig]{Prefix: "TENANT_", Options: []Option{WithStrict()}}.Load()
+	assert.EqualError(t, err, `env: tenant "A": env: unknown environment variables with prefix "TENANT_A_": TENANT_A_PROT`)
+}
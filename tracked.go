@@ -0,0 +1,106 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Origin describes where a value came from.
+type Origin string
+
+const (
+	// OriginEnv is the origin of values read from the Source.
+	OriginEnv Origin = "env"
+	// OriginDefault is the origin of values taken from `envDefault`.
+	OriginDefault Origin = "default"
+)
+
+// Tracked holds a parsed value along with the variable it came from, so that
+// code using the value can refer to its origin, e.g. in error messages.
+//
+// A Tracked[T] field is parsed exactly like a T field with the same tags.
+type Tracked[T any] struct {
+	value  T
+	key    string
+	origin Origin
+	raw    string
+}
+
+// Value returns the parsed value.
+func (t Tracked[T]) Value() T {
+	return t.value
+}
+
+// Key returns the full name of the variable the value was read from,
+// prefix included.
+func (t Tracked[T]) Key() string {
+	return t.key
+}
+
+// Origin returns where the value came from, or an empty Origin if the
+// variable was not set and had no default.
+func (t Tracked[T]) Origin() Origin {
+	return t.origin
+}
+
+// Raw returns the string the value was parsed from.
+func (t Tracked[T]) Raw() string {
+	return t.raw
+}
+
+// String formats the value.
+func (t Tracked[T]) String() string {
+	return fmt.Sprint(t.value)
+}
+
+// GoString formats the value along with its origin.
+func (t Tracked[T]) GoString() string {
+	return fmt.Sprintf("env.Tracked[%T]{Key: %q, Origin: %q, Raw: %q, Value: %#v}", t.value, t.key, t.origin, t.raw, t.value)
+}
+
+// Errorf formats an error about the value, mentioning the variable it came
+// from, e.g. `invalid bucket name from S3_BUCKET`.
+func (t Tracked[T]) Errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s from %s", fmt.Sprintf(format, args...), t.key)
+}
+
+func (t *Tracked[T]) valuePtr() reflect.Value {
+	return reflect.ValueOf(&t.value)
+}
+
+func (t *Tracked[T]) track(key string, origin Origin, raw string) {
+	t.key = key
+	t.origin = origin
+	t.raw = raw
+}
+
+// tracker is implemented by pointers to Tracked values.
+type tracker interface {
+	valuePtr() reflect.Value
+	track(key string, origin Origin, raw string)
+}
+
+func asTracker(field reflect.Value) tracker {
+	if !field.CanAddr() {
+		return nil
+	}
+	tr, _ := field.Addr().Interface().(tracker)
+	return tr
+}
+
+// parseTracked parses a Tracked field as if it was a field of the wrapped
+// type, then records where the value came from.
+func (p *parser) parseTracked(prefix, path string, tr tracker, sf reflect.StructField) error {
+	value, origin, err := p.get(prefix, path, sf)
+	if err != nil {
+		return err
+	}
+	params, _ := p.fieldParams(prefix, path, sf)
+	tr.track(params.Key, origin, value)
+	if value == "" {
+		return nil
+	}
+	field := tr.valuePtr().Elem()
+	sf.Type = field.Type()
+	return p.setField(prefix, path, field, sf, value)
+}
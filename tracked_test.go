@@ -0,0 +1,68 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracked(t *testing.T) {
+	type config struct {
+		Bucket  Tracked[string]         `env:"S3_BUCKET"`
+		Port    Tracked[int]            `env:"PORT" envDefault:"3000"`
+		Hosts   Tracked[[]string]       `env:"HOSTS"`
+		Timeout Tracked[*time.Duration] `env:"TIMEOUT"`
+		Level   Tracked[LogLevel]       `env:"LOG_LEVEL"`
+		Unset   Tracked[string]         `env:"UNSET"`
+		Inner   struct {
+			Name Tracked[string] `env:"NAME"`
+		} `envPrefix:"INNER_"`
+	}
+	defer os.Clearenv()
+	os.Setenv("APP_S3_BUCKET", "my_bucket")
+	os.Setenv("APP_HOSTS", "a,b")
+	os.Setenv("APP_TIMEOUT", "1s")
+	os.Setenv("APP_LOG_LEVEL", "debug")
+	os.Setenv("APP_INNER_NAME", "foo")
+
+	var cfg config
+	assert.NoError(t, ParsePrefix("APP_", &cfg))
+
+	assert.Equal(t, "my_bucket", cfg.Bucket.Value())
+	assert.Equal(t, "APP_S3_BUCKET", cfg.Bucket.Key())
+	assert.Equal(t, OriginEnv, cfg.Bucket.Origin())
+	assert.Equal(t, "my_bucket", cfg.Bucket.Raw())
+	assert.EqualError(t, cfg.Bucket.Errorf("invalid bucket name %q", cfg.Bucket), `invalid bucket name "my_bucket" from APP_S3_BUCKET`)
+
+	assert.Equal(t, 3000, cfg.Port.Value())
+	assert.Equal(t, OriginDefault, cfg.Port.Origin())
+	assert.Equal(t, "3000", cfg.Port.String())
+	assert.Equal(t, `env.Tracked[int]{Key: "APP_PORT", Origin: "default", Raw: "3000", Value: 3000}`, fmt.Sprintf("%#v", cfg.Port))
+
+	assert.Equal(t, []string{"a", "b"}, cfg.Hosts.Value())
+	assert.Equal(t, time.Second, *cfg.Timeout.Value())
+	assert.Equal(t, DebugLevel, cfg.Level.Value())
+
+	assert.Equal(t, "", cfg.Unset.Value())
+	assert.Equal(t, "APP_UNSET", cfg.Unset.Key())
+	assert.Equal(t, Origin(""), cfg.Unset.Origin())
+
+	assert.Equal(t, "foo", cfg.Inner.Name.Value())
+	assert.Equal(t, "APP_INNER_NAME", cfg.Inner.Name.Key())
+}
+
+func TestTrackedErrors(t *testing.T) {
+	type config struct {
+		Port Tracked[int] `env:"PORT,required"`
+	}
+	defer os.Clearenv()
+
+	var cfg config
+	assert.EqualError(t, Parse(&cfg), `env: required environment variable "PORT" is not set`)
+
+	os.Setenv("PORT", "nope")
+	assert.EqualError(t, Parse(&cfg), `env: parse error on field "Port" of type "int" (PORT="nope"): strconv.ParseInt: parsing "nope": invalid syntax`)
+}
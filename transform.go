@@ -0,0 +1,156 @@
+package env
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WithTrimSpace makes Parse strip leading and trailing white space from
+// every value before parsing it, as the `trim` tag option does for a single
+// field, e.g. the trailing newline of values pasted into the secrets of a
+// CI system.
+func WithTrimSpace() Option {
+	return func(o *Options) {
+		o.TrimSpace = true
+	}
+}
+
+// WithUnquote makes Parse remove one pair of matching single or double
+// quotes surrounding every value before parsing it, as the `unquote` tag
+// option does for a single field, for platforms delivering values such as
+// "8080" with their quotes. The quotes are removed as they are: escape
+// sequences are not interpreted.
+func WithUnquote() Option {
+	return func(o *Options) {
+		o.Unquote = true
+	}
+}
+
+// transform applies to val, the value of the field described by params, the
+// changes requested by its tag options, before it is parsed.
+func (p *parser) transform(params FieldParams, val string) (string, error) {
+	if params.Trim {
+		val = strings.TrimSpace(val)
+	}
+	if params.Unquote {
+		val = unquote(val)
+	}
+	if params.Multiline {
+		val = lineBreaks.Replace(val)
+	}
+	if val == "" {
+		return val, nil
+	}
+	for i := len(params.Encodings) - 1; i >= 0; i-- {
+		enc := params.Encodings[i]
+		b, err := codecs[enc].decode([]byte(val), p.MaxValueLength)
+		if err != nil {
+			return "", fmt.Errorf(`env: could not decode the %s value of environment variable %q: %v`, enc, params.Key, err)
+		}
+		val = string(b)
+	}
+	return val, nil
+}
+
+// lineBreaks replaces the escaped line breaks of the values of fields with
+// the `multiline` option by newlines, and escaped backslashes by
+// backslashes, so that `\\n` stays a backslash followed by n. Other
+// backslashes are kept.
+// nolint: gochecknoglobals
+var lineBreaks = strings.NewReplacer(`\\`, `\`, `\r\n`, "\n", `\n`, "\n")
+
+// escapeLineBreaks reverses lineBreaks.
+// nolint: gochecknoglobals
+var escapeLineBreaks = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// codec transforms values, e.g. to fit large ones in variables.
+type codec struct {
+	encode func(b []byte) ([]byte, error)
+	// decode reverses encode. If limit is positive, it fails on values
+	// longer than limit once decoded.
+	decode func(b []byte, limit int) ([]byte, error)
+}
+
+// codecs holds the encodings supported by the `envEncoding` tag.
+// nolint: gochecknoglobals
+var codecs = map[string]codec{
+	"base64": {
+		encode: func(b []byte) ([]byte, error) {
+			return []byte(base64.StdEncoding.EncodeToString(b)), nil
+		},
+		decode: func(b []byte, _ int) ([]byte, error) {
+			return decodeBase64(string(b))
+		},
+	},
+	"gzip": {
+		encode: func(b []byte) ([]byte, error) {
+			var buf bytes.Buffer
+			w := gzip.NewWriter(&buf)
+			if _, err := w.Write(b); err != nil {
+				return nil, err
+			}
+			if err := w.Close(); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		},
+		decode: func(b []byte, limit int) ([]byte, error) {
+			r, err := gzip.NewReader(bytes.NewReader(b))
+			if err != nil {
+				return nil, err
+			}
+			var lr io.Reader = r
+			if limit > 0 {
+				lr = io.LimitReader(r, int64(limit)+1)
+			}
+			out, err := io.ReadAll(lr)
+			if err != nil {
+				return nil, err
+			}
+			if limit > 0 && len(out) > limit {
+				return nil, fmt.Errorf("decompressed value is longer than %d bytes", limit)
+			}
+			return out, nil
+		},
+	},
+}
+
+// encodeValue applies the encodings of params to value, for Marshal.
+func encodeValue(params FieldParams, value string) (string, error) {
+	b := []byte(value)
+	for _, enc := range params.Encodings {
+		var err error
+		if b, err = codecs[enc].encode(b); err != nil {
+			return "", err
+		}
+	}
+	if params.Multiline {
+		return escapeLineBreaks.Replace(string(b)), nil
+	}
+	return string(b), nil
+}
+
+// decodeBase64 decodes s, encoded with the standard or the URL-safe
+// alphabet, with or without padding. White space, such as the line breaks
+// of wrapped output, is ignored.
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.Join(strings.Fields(s), "")
+	enc := base64.StdEncoding
+	if strings.ContainsAny(s, "-_") {
+		enc = base64.URLEncoding
+	}
+	return enc.WithPadding(base64.NoPadding).DecodeString(strings.TrimRight(s, "="))
+}
+
+// unquote removes one pair of matching single or double quotes surrounding
+// s, if any.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,160 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrim(t *testing.T) {
+	type config struct {
+		Port  int      `env:"PORT,trim"`
+		Hosts []string `env:"HOSTS,trim"`
+		Name  string   `env:"NAME"`
+		Token string   `env:"TOKEN_FILE,file,trim"`
+	}
+	file := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(file, []byte("secret\n"), 0o600))
+	src := MapSource{"PORT": " 8080\n", "HOSTS": "\ta,b ", "NAME": " x ", "TOKEN_FILE": file}
+
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(src)))
+	assert.Equal(t, config{Port: 8080, Hosts: []string{"a", "b"}, Name: " x ", Token: "secret"}, cfg)
+
+	cfg = config{}
+	require.NoError(t, Parse(&cfg, WithSource(src), WithTrimSpace()))
+	assert.Equal(t, "x", cfg.Name)
+
+	type untrimmed struct {
+		Port int `env:"PORT"`
+	}
+	assert.Error(t, Parse(&untrimmed{}, WithSource(src)))
+}
+
+func TestUnquote(t *testing.T) {
+	type config struct {
+		Port  int    `env:"PORT,unquote"`
+		Name  string `env:"NAME,trim,unquote"`
+		Other string `env:"OTHER"`
+	}
+	src := MapSource{"PORT": `"8080"`, "NAME": " 'a \"b\"' \n", "OTHER": `'x'`}
+
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(src)))
+	assert.Equal(t, config{Port: 8080, Name: `a "b"`, Other: `'x'`}, cfg)
+
+	require.NoError(t, Parse(&cfg, WithSource(src), WithUnquote()))
+	assert.Equal(t, "x", cfg.Other)
+
+	for in, want := range map[string]string{`"a"`: "a", `'a'`: "a", `""`: "", `"a'`: `"a'`, `"`: `"`, `a"`: `a"`, `"a"b"`: `a"b`} {
+		assert.Equal(t, want, unquote(in), in)
+	}
+}
+
+func TestMultiline(t *testing.T) {
+	type config struct {
+		Key   string   `env:"KEY,unquote,multiline"`
+		Lines []string `env:"LINES,multiline" envSeparator:"\n"`
+		Other string   `env:"OTHER"`
+	}
+	src := MapSource{
+		"KEY":   `"-----BEGIN KEY-----\nMIIB\r\n-----END KEY-----\n"`,
+		"LINES": `a\nb\nc`,
+		"OTHER": `a\nb`,
+	}
+
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(src)))
+	assert.Equal(t, config{
+		Key:   "-----BEGIN KEY-----\nMIIB\n-----END KEY-----\n",
+		Lines: []string{"a", "b", "c"},
+		Other: `a\nb`,
+	}, cfg)
+
+	type path struct {
+		Path string `env:"PATH,multiline"`
+	}
+	var p path
+	require.NoError(t, Parse(&p, WithSource(MapSource{"PATH": `C:\\new\tmp\n`})))
+	assert.Equal(t, "C:\\new\\tmp\n", p.Path)
+
+	vars, err := Marshal(path{Path: "C:\\new\nline\r\n"})
+	require.NoError(t, err)
+	assert.Equal(t, `C:\\new\nline`+"\r"+`\n`, vars["PATH"])
+	require.NoError(t, Parse(&p, WithSource(MapSource(vars))))
+	assert.Equal(t, "C:\\new\nline\r\n", p.Path)
+}
+
+func TestBase64(t *testing.T) {
+	type config struct {
+		Token   string `env:"TOKEN,base64"`
+		Port    int    `env:"PORT,trim,base64"`
+		Cert    string `env:"CERT,base64"`
+		Unset   string `env:"UNSET,base64"`
+		Invalid string `env:"INVALID"`
+	}
+	src := MapSource{
+		"TOKEN":   "c2VjcmV0Pz4-", // URL-safe alphabet
+		"PORT":    " ODA4MA==\n",
+		"CERT":    "LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0t\nYWJj",
+		"INVALID": "!!",
+	}
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(src)))
+	assert.Equal(t, config{Token: "secret?>>", Port: 8080, Cert: "-----BEGIN CERTIFICATE-----abc", Invalid: "!!"}, cfg)
+
+	type invalid struct {
+		Value string `env:"INVALID,base64"`
+	}
+	err := Parse(&invalid{}, WithSource(src))
+	assert.EqualError(t, err, `env: could not decode the base64 value of environment variable "INVALID": illegal base64 data at input byte 0`)
+
+	vars, err := Marshal(config{Token: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, "c2VjcmV0", vars["TOKEN"])
+}
+
+func TestEncoding(t *testing.T) {
+	type config struct {
+		Bundle string `env:"BUNDLE" envEncoding:"gzip,base64"`
+	}
+	bundle := strings.Repeat("-----BEGIN CERTIFICATE-----\nMIIB...\n-----END CERTIFICATE-----\n", 100)
+	vars, err := Marshal(config{Bundle: bundle})
+	require.NoError(t, err)
+	assert.Less(t, len(vars["BUNDLE"]), len(bundle)/10)
+
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(MapSource(vars))))
+	assert.Equal(t, bundle, cfg.Bundle)
+
+	err = Parse(&cfg, WithSource(MapSource(vars)), WithMaxValueLength(1000))
+	assert.EqualError(t, err, `env: could not decode the gzip value of environment variable "BUNDLE": decompressed value is longer than 1000 bytes`)
+
+	err = Parse(&cfg, WithSource(MapSource{"BUNDLE": "aGVsbG8="}))
+	assert.EqualError(t, err, `env: could not decode the gzip value of environment variable "BUNDLE": unexpected EOF`)
+
+	type unsupported struct {
+		Value string `env:"VALUE" envEncoding:"zstd"`
+	}
+	assert.EqualError(t, Parse(&unsupported{}, WithSource(MapSource{})), `env: envEncoding "zstd" not supported`)
+
+	type binary struct {
+		Value string `env:"VALUE" envEncoding:"base64,gzip"`
+	}
+	assert.EqualError(t, Parse(&binary{}, WithSource(MapSource{})), `env: envEncoding "base64,gzip" must end with base64, as variables cannot hold binary values`)
+	_, err = Marshal(binary{Value: "x"})
+	assert.Error(t, err)
+
+	type tagged struct {
+		Value string `env:"VALUE,base64" envEncoding:"gzip"`
+	}
+	vars, err = Marshal(tagged{Value: "x"})
+	require.NoError(t, err)
+	var back tagged
+	require.NoError(t, Parse(&back, WithSource(MapSource(vars))))
+	assert.Equal(t, "x", back.Value)
+}
@@ -0,0 +1,99 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Unsetter is implemented by sources whose variables can be removed, such as
+// OSSource.
+type Unsetter interface {
+	// Unset removes the variable named by key. It is not an error if the
+	// variable is not set.
+	Unset(key string) error
+}
+
+// ErrNotUnsettable is returned by Parse with WithUnset for sources that are
+// not Unsetters.
+var ErrNotUnsettable = errors.New("env: source cannot unset its variables")
+
+// WithUnset makes Parse unset every variable it read, once it succeeded, so
+// that secrets are neither inherited by child processes nor visible in
+// /proc afterwards. Variables holding the path of a `file` field are unset,
+// while the ones only referred to by `envExpand` values are kept.
+//
+// The Source must be an Unsetter; sources combined with ChainSource are
+// unset together, skipping the ones that are not Unsetters.
+func WithUnset() Option {
+	return func(o *Options) {
+		o.Unset = true
+	}
+}
+
+// unsetUsed unsets the variables read by the parser from its Source.
+func (p *parser) unsetUsed() error {
+	u, ok := p.Source.(Unsetter)
+	if !ok {
+		return ErrNotUnsettable
+	}
+	keys := make([]string, 0, len(p.used))
+	for key := range p.used {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := u.Unset(key); err != nil {
+			return fmt.Errorf("env: could not unset %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// Unset calls os.Unsetenv.
+func (OSSource) Unset(key string) error {
+	return os.Unsetenv(key)
+}
+
+// Unset deletes key from the map.
+func (m MapSource) Unset(key string) error {
+	delete(m, key)
+	return nil
+}
+
+// Unset unsets key in the wrapped Source and drops its cached value.
+func (c *SourceCache) Unset(key string) error {
+	u, ok := c.source.(Unsetter)
+	if !ok {
+		return ErrNotUnsettable
+	}
+	if err := u.Unset(key); err != nil {
+		return err
+	}
+	c.Invalidate(key)
+	return nil
+}
+
+// Unset unsets key in each of the sources that are Unsetters.
+func (c chainSource) Unset(key string) error {
+	unsettable := false
+	for _, s := range c {
+		u, ok := s.(Unsetter)
+		if !ok {
+			continue
+		}
+		err := u.Unset(key)
+		if errors.Is(err, ErrNotUnsettable) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		unsettable = true
+	}
+	if !unsettable {
+		return ErrNotUnsettable
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnset(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+
+	cert := filepath.Join(t.TempDir(), "cert.pem")
+	require.NoError(t, os.WriteFile(cert, []byte("-----BEGIN CERTIFICATE-----"), 0o600))
+	os.Setenv("APP_PASSWORD", "hunter2")
+	os.Setenv("APP_CERT", cert)
+	os.Setenv("APP_URL", "postgres://${HOME}/db")
+	os.Setenv("HOME", "/home/user")
+	os.Setenv("OTHER", "kept")
+
+	type config struct {
+		Password string `env:"PASSWORD,sensitive"`
+		Cert     string `env:"CERT,file"`
+		URL      string `env:"URL" envExpand:"true"`
+		Port     int    `env:"PORT" envDefault:"8080"`
+	}
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithPrefix("APP_"), WithUnset()))
+	assert.Equal(t, config{Password: "hunter2", Cert: "-----BEGIN CERTIFICATE-----", URL: "postgres:///home/user/db", Port: 8080}, cfg)
+
+	assert.Equal(t, []string{"HOME=/home/user", "OTHER=kept"}, os.Environ())
+}
+
+func TestUnsetOnError(t *testing.T) {
+	source := MapSource{"PASSWORD": "hunter2", "PORT": "nope"}
+	type config struct {
+		Password string `env:"PASSWORD"`
+		Port     int    `env:"PORT"`
+	}
+	var cfg config
+	require.Error(t, Parse(&cfg, WithSource(source), WithUnset()))
+	assert.Equal(t, MapSource{"PASSWORD": "hunter2", "PORT": "nope"}, source)
+}
+
+func TestUnsetSources(t *testing.T) {
+	type config struct {
+		Password string `env:"PASSWORD"`
+	}
+
+	m := MapSource{"PASSWORD": "hunter2"}
+	cache := CachedSource(m, time.Hour)
+	var cfg config
+	require.NoError(t, Parse(&cfg, WithSource(ChainSource(cache, SourceFunc(func(string) (string, bool) {
+		return "", false
+	}))), WithUnset()))
+	assert.Equal(t, "hunter2", cfg.Password)
+	assert.Empty(t, m)
+	_, ok := cache.Lookup("PASSWORD")
+	assert.False(t, ok)
+
+	err := Parse(&cfg, WithSource(SourceFunc(func(string) (string, bool) {
+		return "", false
+	})), WithUnset())
+	assert.Equal(t, ErrNotUnsettable, err)
+}
@@ -0,0 +1,51 @@
+package env
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Usage writes to w an aligned table of the variables Parse reads for v, a
+// struct or a pointer to a struct, with their type, default, whether they
+// are required and their `envDocs` description, e.g. to document the
+// environment of a program in its --help output, the way flag.PrintDefaults
+// does for flags:
+//
+//	flag.Usage = func() {
+//		flag.PrintDefaults()
+//		fmt.Fprintln(os.Stderr, "\nEnvironment variables:")
+//		env.Usage(os.Stderr, &cfg, env.WithPrefix("APP_"))
+//	}
+func Usage(w io.Writer, v interface{}, opts ...Option) error {
+	infos, err := Describe(v, opts...)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VARIABLE\tTYPE\tDEFAULT\tREQUIRED\tDESCRIPTION")
+	for _, info := range infos {
+		var def, required string
+		if info.HasDefaultValue {
+			def = fmt.Sprintf("%q", info.DefaultValue)
+		}
+		if info.Required {
+			required = "yes"
+		}
+		docs := strings.Join(strings.Fields(info.Docs), " ")
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", info.Key, info.Type, def, required, docs)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	// drop the padding of empty trailing cells
+	lines := strings.SplitAfter(buf.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \n")
+	}
+	_, err = io.WriteString(w, strings.Join(lines, "\n"))
+	return err
+}
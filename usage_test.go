@@ -0,0 +1,30 @@
+package env
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsage(t *testing.T) {
+	type config struct {
+		Port    int           `env:"PORT" envDefault:"8080" envDocs:"Port to listen on"`
+		DBURL   string        `env:"DATABASE_URL,required" envDocs:"Connection string,\n  with credentials"`
+		Timeout time.Duration `env:"TIMEOUT" envDefault:"5s"`
+		Hosts   []string      `env:"HOSTS"`
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Usage(&buf, &config{}, WithPrefix("APP_")))
+	assert.Equal(t, `VARIABLE          TYPE           DEFAULT  REQUIRED  DESCRIPTION
+APP_PORT          int            "8080"             Port to listen on
+APP_DATABASE_URL  string                  yes       Connection string, with credentials
+APP_TIMEOUT       time.Duration  "5s"
+APP_HOSTS         []string
+`, buf.String())
+
+	assert.EqualError(t, Usage(&buf, "nope"), "env: expected a struct or a pointer to a struct, got string")
+}
@@ -0,0 +1,191 @@
+// Package vault provides an env.Source backed by the KV secrets engine of
+// HashiCorp Vault.
+//
+// To keep the Vault API out of the dependencies of applications that do not
+// need it, the package reads secrets through the one-method Client
+// interface, which the logical client of the Vault API implements with a
+// small adapter:
+//
+//	type client struct{ *api.Logical }
+//
+//	func (c client) Read(ctx context.Context, path string) (map[string]interface{}, error) {
+//		secret, err := c.ReadWithContext(ctx, path)
+//		if err != nil || secret == nil {
+//			return nil, err
+//		}
+//		return secret.Data, nil
+//	}
+package vault
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/conradludgate/env/v6"
+	"github.com/conradludgate/env/v6/internal/memo"
+)
+
+// ErrPermissionDenied is returned, possibly wrapped, by clients when Vault
+// rejects the token, e.g. because it expired.
+var ErrPermissionDenied = errors.New("vault: permission denied")
+
+// Client reads secrets from Vault.
+type Client interface {
+	// Read returns the data of the secret at path, or nil if there is none.
+	Read(ctx context.Context, path string) (map[string]interface{}, error)
+}
+
+// Source is an env.Source resolving variables from fields of Vault secrets.
+// Secrets are read on first use and cached, so a Source is meant to be used
+// for a single Parse, or a few at startup.
+type Source struct {
+	// Client reads the secrets.
+	Client Client
+
+	// Path is a text/template rendering the path of the secret holding a
+	// variable, executed with .Key set to the variable name, e.g.
+	// "secret/data/myapp" or "secret/data/myapp/{{lower .Key}}". The
+	// functions lower and upper are available.
+	Path string
+
+	// Field is a template rendering the field of the secret holding the
+	// value, in the same way as Path. Defaults to "{{.Key}}", i.e. every
+	// variable is a field of the secret at Path.
+	Field string
+
+	// Login, if set, is called when Vault rejects the token, to log in
+	// again, after which the read is retried once. Reads rejected together
+	// share a single call. The token is not renewed ahead of its expiry.
+	Login func(ctx context.Context) error
+
+	mu          sync.Mutex
+	path, field *template.Template
+	secrets     memo.Cache[map[string]interface{}]
+
+	// loginMu serializes calls to Login, logins counting them, so that a
+	// read rejected before the last login retries without logging in again.
+	loginMu sync.Mutex
+	logins  int
+}
+
+var _ env.ContextSource = (*Source)(nil)
+
+// New returns a Source reading variables from the fields of the secret at
+// path, a template as described by Source.Path.
+func New(client Client, path string) *Source {
+	return &Source{Client: client, Path: path}
+}
+
+// Lookup retrieves the value of the variable named by key. Errors are
+// ignored; env.Parse reports them through LookupContext.
+func (s *Source) Lookup(key string) (string, bool) {
+	value, ok, _ := s.LookupContext(context.Background(), key)
+	return value, ok
+}
+
+// LookupContext retrieves the value of the variable named by key. Lookups
+// of different secrets run concurrently, e.g. with env.WithConcurrency,
+// while concurrent lookups of the same secret share one read.
+func (s *Source) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	err := s.parseTemplates()
+	pathTmpl, fieldTmpl := s.path, s.field
+	s.mu.Unlock()
+	if err != nil {
+		return "", false, err
+	}
+	path, err := render(pathTmpl, key)
+	if err != nil {
+		return "", false, err
+	}
+	field, err := render(fieldTmpl, key)
+	if err != nil {
+		return "", false, err
+	}
+	data, err := s.secrets.Get(ctx, path, func(ctx context.Context) (map[string]interface{}, error) {
+		return s.read(ctx, path)
+	})
+	if err != nil {
+		return "", false, err
+	}
+	v, ok := data[field]
+	if !ok || v == nil {
+		return "", false, nil
+	}
+	if str, isString := v.(string); isString {
+		return str, true, nil
+	}
+	return fmt.Sprint(v), true, nil
+}
+
+func (s *Source) parseTemplates() error {
+	if s.path != nil {
+		return nil
+	}
+	funcs := template.FuncMap{"lower": strings.ToLower, "upper": strings.ToUpper}
+	field := s.Field
+	if field == "" {
+		field = "{{.Key}}"
+	}
+	var err error
+	if s.path, err = template.New("path").Funcs(funcs).Parse(s.Path); err != nil {
+		return fmt.Errorf("vault: %w", err)
+	}
+	if s.field, err = template.New("field").Funcs(funcs).Parse(field); err != nil {
+		s.path = nil
+		return fmt.Errorf("vault: %w", err)
+	}
+	return nil
+}
+
+func render(tmpl *template.Template, key string) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct{ Key string }{key}); err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// read reads the data of the secret at path from Vault, unwrapping the data
+// of KV version 2 secrets.
+func (s *Source) read(ctx context.Context, path string) (map[string]interface{}, error) {
+	s.loginMu.Lock()
+	logins := s.logins
+	s.loginMu.Unlock()
+	data, err := s.Client.Read(ctx, path)
+	if errors.Is(err, ErrPermissionDenied) && s.Login != nil {
+		if err := s.login(ctx, logins); err != nil {
+			return nil, fmt.Errorf("vault: login: %w", err)
+		}
+		data, err = s.Client.Read(ctx, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = inner
+		}
+	}
+	return data, nil
+}
+
+// login calls Login, unless another read already did since the caller saw
+// logins, in which case the caller retries with the token it got.
+func (s *Source) login(ctx context.Context, logins int) error {
+	s.loginMu.Lock()
+	defer s.loginMu.Unlock()
+	if s.logins != logins {
+		return nil
+	}
+	if err := s.Login(ctx); err != nil {
+		return err
+	}
+	s.logins++
+	return nil
+}
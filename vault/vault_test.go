@@ -0,0 +1,137 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/conradludgate/env/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	secrets map[string]map[string]interface{}
+	token   string
+	reads   int
+}
+
+func (c *fakeClient) Read(_ context.Context, path string) (map[string]interface{}, error) {
+	c.reads++
+	if c.token != "valid" {
+		return nil, fmt.Errorf("read %s: %w", path, ErrPermissionDenied)
+	}
+	return c.secrets[path], nil
+}
+
+type config struct {
+	Password string `env:"DB_PASSWORD,required"`
+	Port     int    `env:"PORT" envDefault:"3000"`
+	APIKey   string `env:"API_KEY"`
+}
+
+func TestSource(t *testing.T) {
+	client := &fakeClient{token: "valid", secrets: map[string]map[string]interface{}{
+		"secret/data/myapp": {
+			"data":     map[string]interface{}{"DB_PASSWORD": "secret", "PORT": 8080},
+			"metadata": map[string]interface{}{"version": 3},
+		},
+	}}
+
+	var cfg config
+	require.NoError(t, env.Parse(&cfg, env.WithSource(New(client, "secret/data/myapp"))))
+	assert.Equal(t, config{Password: "secret", Port: 8080}, cfg)
+	assert.Equal(t, 1, client.reads)
+}
+
+func TestSourceTemplates(t *testing.T) {
+	client := &fakeClient{token: "valid", secrets: map[string]map[string]interface{}{
+		"kv/myapp/db_password": {"value": "secret"},
+		"kv/myapp/api_key":     {"value": "key"},
+	}}
+	src := &Source{Client: client, Path: "kv/myapp/{{lower .Key}}", Field: "value"}
+
+	var cfg config
+	require.NoError(t, env.Parse(&cfg, env.WithSource(src)))
+	assert.Equal(t, config{Password: "secret", Port: 3000, APIKey: "key"}, cfg)
+
+	_, _, err := (&Source{Client: client, Path: "{{"}).LookupContext(context.Background(), "PORT")
+	assert.Error(t, err)
+}
+
+func TestSourceLogin(t *testing.T) {
+	client := &fakeClient{token: "expired", secrets: map[string]map[string]interface{}{
+		"secret/myapp": {"DB_PASSWORD": "secret"},
+	}}
+	src := New(client, "secret/myapp")
+
+	var cfg config
+	err := env.Parse(&cfg, env.WithSource(src))
+	assert.EqualError(t, err, `env: could not look up "DB_PASSWORD": read secret/myapp: vault: permission denied`)
+
+	src.Login = func(context.Context) error {
+		client.token = "valid"
+		return nil
+	}
+	require.NoError(t, env.Parse(&cfg, env.WithSource(src)))
+	assert.Equal(t, "secret", cfg.Password)
+
+	client.token = "expired"
+	src = New(client, "secret/myapp")
+	src.Login = func(context.Context) error {
+		return errors.New("bad credentials")
+	}
+	err = env.Parse(&cfg, env.WithSource(src))
+	assert.EqualError(t, err, `env: could not look up "DB_PASSWORD": vault: login: bad credentials`)
+}
+
+// expiringClient rejects the first reads until all of them are in flight,
+// then accepts reads once logged in.
+type expiringClient struct {
+	mu       sync.Mutex
+	loggedIn bool
+	rejected sync.WaitGroup
+}
+
+func (c *expiringClient) Read(ctx context.Context, path string) (map[string]interface{}, error) {
+	c.mu.Lock()
+	loggedIn := c.loggedIn
+	c.mu.Unlock()
+	if loggedIn {
+		return map[string]interface{}{"value": path}, nil
+	}
+	c.rejected.Done()
+	c.rejected.Wait()
+	return nil, ErrPermissionDenied
+}
+
+func TestSourceConcurrentLogin(t *testing.T) {
+	client := &expiringClient{}
+	client.rejected.Add(2)
+	var logins int32
+	src := &Source{Client: client, Path: "secret/{{.Key}}", Field: "value"}
+	src.Login = func(context.Context) error {
+		atomic.AddInt32(&logins, 1)
+		client.mu.Lock()
+		client.loggedIn = true
+		client.mu.Unlock()
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"A", "B"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			value, ok, err := src.LookupContext(context.Background(), key)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, "secret/"+key, value)
+		}(key)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), logins)
+}
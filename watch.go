@@ -0,0 +1,268 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+// Change describes a field whose value differs between two configurations,
+// as returned by Diff.
+type Change struct {
+	FieldParams
+
+	// Old and New are the values of the field.
+	Old, New interface{}
+}
+
+// RestartRequired reports whether any of the changes is to a field tagged
+// with `envReload:"restart"`, i.e. whether the program should restart,
+// gracefully, to apply them rather than applying them live.
+func RestartRequired(changes []Change) bool {
+	for _, c := range changes {
+		if c.RestartRequired {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff returns the fields Parse populates whose values differ between old
+// and new, structs or pointers to structs of the same type, in the order of
+// the fields. It accepts the options of Parse that change the keys, such as
+// WithPrefix. Lazy fields, only resolved by Get, are left out.
+//
+// A pointer to a nested struct that is nil on one side only is reported as
+// a single change of the pointer, with an empty Key, rather than compared
+// field by field; so is a nil configuration compared with a non-nil one.
+func Diff(old, new interface{}, opts ...Option) ([]Change, error) {
+	a, b := reflect.ValueOf(old), reflect.ValueOf(new)
+	if !a.IsValid() || !b.IsValid() || a.Type() != b.Type() {
+		return nil, fmt.Errorf("env: cannot compare %T and %T", old, new)
+	}
+	if a.Kind() == reflect.Ptr && a.Type().Elem().Kind() == reflect.Struct && (a.IsNil() || b.IsNil()) {
+		if a.IsNil() == b.IsNil() {
+			return nil, nil
+		}
+		return []Change{{Old: old, New: new}}, nil
+	}
+	a, b = addressable(a), addressable(b)
+	if a.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("env: expected structs or pointers to structs, got %T", old)
+	}
+	p := newParser(opts)
+	var changes []Change
+	if err := p.diff(p.Prefix, "", a, b, &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// addressable returns the value pointed to by v, a non-nil pointer, or an
+// addressable copy of v, so that its unexported fields can be read.
+func addressable(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Ptr {
+		return v.Elem()
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
+
+// diff appends to changes the fields of the structs a and b whose values
+// differ.
+func (p *parser) diff(prefix, path string, a, b reflect.Value, changes *[]Change) error {
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !p.Unexported {
+			continue
+		}
+		fa, fb := readable(a.Field(i)), readable(b.Field(i))
+		params, err := p.fieldParams(prefix, path+sf.Name, sf)
+		if err != nil {
+			return err
+		}
+		if asLazy(fa) != nil {
+			continue
+		}
+		if params.Key != "" {
+			if !reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+				*changes = append(*changes, Change{FieldParams: params, Old: fa.Interface(), New: fb.Interface()})
+			}
+			continue
+		}
+		if sf.Type.Kind() == reflect.Ptr && sf.Type.Elem().Kind() == reflect.Struct {
+			if fa.IsNil() || fb.IsNil() {
+				if fa.IsNil() != fb.IsNil() {
+					*changes = append(*changes, Change{FieldParams: params, Old: fa.Interface(), New: fb.Interface()})
+				}
+				continue
+			}
+			fa, fb = fa.Elem(), fb.Elem()
+		}
+		if fa.Kind() == reflect.Struct {
+			if err := p.diff(prefix+sf.Tag.Get("envPrefix"), path+sf.Name+".", fa, fb, changes); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reset sets the fields of the struct ref that Parse populates to their zero
+// value, leaving nested structs and untagged fields as they are.
+func (p *parser) reset(ref reflect.Value) {
+	t := ref.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !p.Unexported {
+			continue
+		}
+		field := readable(ref.Field(i))
+		if key, _ := parseKeyForOption(sf.Tag.Get("env")); key != "" {
+			field.Set(reflect.Zero(sf.Type))
+			continue
+		}
+		if field.Kind() == reflect.Ptr && !field.IsNil() && field.Elem().Kind() == reflect.Struct {
+			p.reset(field.Elem())
+		} else if field.Kind() == reflect.Struct {
+			p.reset(field)
+		}
+	}
+}
+
+// readable returns field, made readable and settable if it is unexported.
+func readable(field reflect.Value) reflect.Value {
+	if field.CanSet() || !field.CanAddr() {
+		return field
+	}
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+}
+
+// Watch re-parses the configuration in cfg every interval, until ctx is
+// done, and calls onChange with the previous and the new configuration and
+// their differences whenever a field changed, e.g. after a variable of a
+// remote Source or a file loaded with the `file` option was updated. It
+// blocks until ctx is done, and returns ctx's error.
+//
+// Each reload starts from a copy of the current configuration in which the
+// fields Parse populates are reset, so that untagged fields and nested
+// structs are kept while variables that were unset get their default or
+// zero value again. Watch replaces *cfg with the new configuration before
+// calling onChange; code reading cfg from other goroutines must be
+// synchronized with onChange, or use Live instead.
+//
+// Reloads that fail, e.g. because a variable was set to an invalid value,
+// never replace cfg. Depending on the ReloadPolicy, Watch keeps the last
+// good configuration and reports the error to the hook set with
+// WithReloadErrorHook, or WithWarningHook, or stops and returns the error.
+//
+// With WithFileWatch, Watch also reloads as soon as a file loaded with the
+// `file` option changes.
+func Watch[T any](ctx context.Context, cfg *T, interval time.Duration, onChange func(old, new T, changes []Change), opts ...Option) error {
+	o := newParser(opts)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var files fileWatcher
+	var fileChanges <-chan struct{}
+	if o.WatchFiles {
+		w, err := newFileWatcher()
+		if err != nil {
+			return fmt.Errorf("env: could not watch files: %w", err)
+		}
+		defer w.close()
+		files, fileChanges = w, w.changes()
+	}
+	// with WithFileWatch, reload right away to find the files to watch
+	wait := files == nil
+	for {
+		if wait {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			case <-fileChanges:
+			}
+		}
+		wait = true
+		next, changes, paths, err := reload(ctx, *cfg, opts)
+		if files != nil {
+			files.watch(paths)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if o.ReloadPolicy == StopOnError {
+				return err
+			}
+			o.reloadFailed(err)
+			continue
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		old := *cfg
+		*cfg = next
+		onChange(old, next, changes)
+	}
+}
+
+// ReloadPolicy says what Watch does when a reload fails.
+type ReloadPolicy int
+
+const (
+	// KeepLastGood keeps the previous configuration and reports the error,
+	// retrying at the next interval or file change. It is the default.
+	KeepLastGood ReloadPolicy = iota
+	// StopOnError makes Watch return the error, e.g. for the service to
+	// exit rather than run with a configuration that no longer matches its
+	// environment.
+	StopOnError
+)
+
+// WithReloadPolicy sets what Watch does when a reload fails.
+func WithReloadPolicy(policy ReloadPolicy) Option {
+	return func(o *Options) {
+		o.ReloadPolicy = policy
+	}
+}
+
+// WithReloadErrorHook sets a function called by Watch with the errors of the
+// reloads that failed while the last good configuration is kept, e.g. to
+// alert on configuration that will be refused by the next restart.
+func WithReloadErrorHook(fn func(err error)) Option {
+	return func(o *Options) {
+		o.OnReloadError = fn
+	}
+}
+
+func (p *parser) reloadFailed(err error) {
+	if p.OnReloadError != nil {
+		p.OnReloadError(err)
+		return
+	}
+	p.warn(err)
+}
+
+// reload parses a new configuration starting from a copy of cur, and
+// returns it with its differences with cur and the files it loaded.
+func reload[T any](ctx context.Context, cur T, opts []Option) (T, []Change, []string, error) {
+	next := deepCopy(cur)
+	p := newParser(opts)
+	p.ctx = ctx
+	if ref := reflect.ValueOf(&next).Elem(); ref.Kind() == reflect.Struct {
+		p.reset(ref)
+	}
+	if err := p.parse(&next); err != nil {
+		return cur, nil, p.files, err
+	}
+	changes, err := Diff(&cur, &next, opts...)
+	if err != nil {
+		return cur, nil, p.files, err
+	}
+	return next, changes, p.files, nil
+}
@@ -0,0 +1,214 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mutableSource is a Source whose variables can be changed while a Watch is
+// reading it.
+type mutableSource struct {
+	mu   sync.Mutex
+	vars map[string]string
+}
+
+func (s *mutableSource) Lookup(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.vars[key]
+	return v, ok
+}
+
+func (s *mutableSource) set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vars[key] = value
+}
+
+func (s *mutableSource) unset(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.vars, key)
+}
+
+func TestDiff(t *testing.T) {
+	type database struct {
+		URL string `env:"URL"`
+	}
+	type config struct {
+		Port     int       `env:"PORT"`
+		Hosts    []string  `env:"HOSTS"`
+		Database *database `envPrefix:"DB_"`
+		Started  time.Time
+	}
+	old := config{Port: 80, Hosts: []string{"a"}, Database: &database{URL: "x"}}
+	new := config{Port: 80, Hosts: []string{"b"}, Started: time.Now()}
+
+	changes, err := Diff(old, &new, WithPrefix("APP_"))
+	assert.Error(t, err)
+	assert.Nil(t, changes)
+
+	changes, err = Diff(old, new, WithPrefix("APP_"))
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "APP_HOSTS", changes[0].Key)
+	assert.Equal(t, "Hosts", changes[0].Field)
+	assert.Equal(t, []string{"a"}, changes[0].Old)
+	assert.Equal(t, []string{"b"}, changes[0].New)
+	assert.Equal(t, "", changes[1].Key)
+	assert.Equal(t, "Database", changes[1].Field)
+	assert.Equal(t, old.Database, changes[1].Old)
+	assert.Nil(t, changes[1].New)
+
+	new.Hosts, new.Database = old.Hosts, &database{}
+	changes, err = Diff(old, new, WithPrefix("APP_"))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "APP_DB_URL", changes[0].Key)
+	assert.Equal(t, "x", changes[0].Old)
+	assert.Equal(t, "", changes[0].New)
+
+	changes, err = Diff((*config)(nil), &new)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, &new, changes[0].New)
+}
+
+func TestWatch(t *testing.T) {
+	type config struct {
+		Port  int    `env:"PORT" envDefault:"80"`
+		Name  string `env:"NAME"`
+		Other string
+	}
+	src := &mutableSource{vars: map[string]string{"PORT": "8080", "NAME": "a"}}
+	cfg := config{Other: "kept"}
+	require.NoError(t, Parse(&cfg, WithSource(src)))
+
+	type event struct {
+		old, new config
+		changes  []Change
+	}
+	events := make(chan event)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		done <- Watch(ctx, &cfg, time.Millisecond, func(old, new config, changes []Change) {
+			events <- event{old, new, changes}
+		}, WithSource(src))
+	}()
+
+	src.set("PORT", "9090")
+	e := <-events
+	assert.Equal(t, 8080, e.old.Port)
+	assert.Equal(t, config{Port: 9090, Name: "a", Other: "kept"}, e.new)
+	require.Len(t, e.changes, 1)
+	assert.Equal(t, "PORT", e.changes[0].Key)
+
+	src.unset("PORT")
+	src.unset("NAME")
+	e = <-events
+	assert.Equal(t, config{Port: 80, Other: "kept"}, e.new)
+	assert.Len(t, e.changes, 2)
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+	assert.Equal(t, config{Port: 80, Other: "kept"}, cfg)
+}
+
+func TestWatchError(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT"`
+	}
+	src := &mutableSource{vars: map[string]string{"PORT": "http"}}
+	var cfg config
+	ctx, cancel := context.WithCancel(context.Background())
+	warnings := make(chan error, 1)
+	err := Watch(ctx, &cfg, time.Millisecond, func(old, new config, changes []Change) {
+		t.Error("unexpected change")
+	}, WithSource(src), WithWarningHook(func(err error) {
+		select {
+		case warnings <- err:
+		default:
+		}
+		cancel()
+	}))
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Error(t, <-warnings)
+	assert.Equal(t, config{}, cfg)
+}
+
+func TestWatchKeepLastGood(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT"`
+	}
+	src := &mutableSource{vars: map[string]string{"PORT": "8080"}}
+	cfg := config{Port: 8080}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloadErrors := make(chan error)
+	ports := make(chan int)
+	done := make(chan error)
+	go func() {
+		done <- Watch(ctx, &cfg, time.Millisecond, func(old, new config, changes []Change) {
+			ports <- new.Port
+		}, WithSource(src), WithWarningHook(func(err error) {
+			t.Errorf("unexpected warning: %v", err)
+		}), WithReloadErrorHook(func(err error) {
+			select {
+			case reloadErrors <- err:
+			default:
+			}
+		}))
+	}()
+
+	src.set("PORT", "http")
+	assert.Error(t, <-reloadErrors)
+	src.set("PORT", "9090")
+	assert.Equal(t, 9090, <-ports)
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+	assert.Equal(t, config{Port: 9090}, cfg)
+}
+
+func TestWatchStopOnError(t *testing.T) {
+	type config struct {
+		Port int `env:"PORT"`
+	}
+	src := &mutableSource{vars: map[string]string{"PORT": "http"}}
+	cfg := config{Port: 8080}
+	err := Watch(context.Background(), &cfg, time.Millisecond, func(old, new config, changes []Change) {
+		t.Error("unexpected change")
+	}, WithSource(src), WithReloadPolicy(StopOnError))
+	assert.EqualError(t, err, `env: parse error on field "Port" of type "int" (PORT="http"): strconv.ParseInt: parsing "http": invalid syntax`)
+	assert.Equal(t, config{Port: 8080}, cfg)
+}
+
+func TestRestartRequired(t *testing.T) {
+	type config struct {
+		Addr     string `env:"ADDR" envReload:"restart"`
+		LogLevel string `env:"LOG_LEVEL" envReload:"hot"`
+		Timeout  int    `env:"TIMEOUT"`
+	}
+	changes, err := Diff(config{Addr: ":80", LogLevel: "info"}, config{Addr: ":80", LogLevel: "debug", Timeout: 1})
+	require.NoError(t, err)
+	assert.Len(t, changes, 2)
+	assert.False(t, RestartRequired(changes))
+
+	changes, err = Diff(config{Addr: ":80"}, config{Addr: ":8080"})
+	require.NoError(t, err)
+	assert.True(t, changes[0].RestartRequired)
+	assert.True(t, RestartRequired(changes))
+
+	type invalid struct {
+		Addr string `env:"ADDR" envReload:"never"`
+	}
+	err = Parse(&invalid{}, WithSource(MapSource{}))
+	assert.EqualError(t, err, `env: envReload "never" not supported, expected hot or restart`)
+}